@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Key はHTTPトランスポートに接続できるクライアントを表します
+type Key struct {
+	// Name はログなどに使うクライアントの名前です
+	Name string `json:"name"`
+	// Key はクライアントが Authorization: Bearer ヘッダで送る値です
+	Key string `json:"key"`
+	// DocBaseToken はこのクライアントが既定のチームで使うDocBaseのAPIトークンです
+	DocBaseToken string `json:"docbase_token"`
+	// DocBaseTokens はこのクライアントがチームごとに使うDocBaseのAPIトークンです
+	DocBaseTokens map[string]string `json:"docbase_tokens"`
+}
+
+// HasDocBaseTokens はクライアント自身のDocBaseのAPIトークンが設定されているかを返します
+// 設定されていない場合、ツール呼び出しにはチームで共有するトークンを使います
+func (k *Key) HasDocBaseTokens() bool {
+	return k.DocBaseToken != "" || len(k.DocBaseTokens) > 0
+}
+
+// Keys は接続を許可するクライアントの一覧です
+type Keys struct {
+	keys []Key
+}
+
+// NewKeys は keys を検証して Keys を作成します
+func NewKeys(keys []Key) (*Keys, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		if k.Key == "" {
+			return nil, fmt.Errorf("key #%d (%s) is empty", i+1, k.Name)
+		}
+		if seen[k.Key] {
+			return nil, fmt.Errorf("key #%d (%s) is duplicated", i+1, k.Name)
+		}
+		seen[k.Key] = true
+	}
+
+	return &Keys{keys: keys}, nil
+}
+
+// LoadKeys はJSONファイルからクライアントの一覧を読み込みます
+//
+//	{"keys": [{"name": "alice", "key": "...", "docbase_tokens": {"prod": "...", "sandbox": "..."}}]}
+func LoadKeys(path string) (*Keys, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+
+	var file struct {
+		Keys []Key `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keys file: %w", err)
+	}
+
+	return NewKeys(file.Keys)
+}
+
+// Authenticate はリクエストの Authorization ヘッダに対応するクライアントを返します
+func (k *Keys) Authenticate(r *http.Request) (*Key, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, false
+	}
+
+	// どのキーと一致したかでタイミングが変わらないよう、すべてのキーと比較する
+	var found *Key
+	for i := range k.keys {
+		if subtle.ConstantTimeCompare([]byte(k.keys[i].Key), []byte(token)) == 1 {
+			found = &k.keys[i]
+		}
+	}
+
+	return found, found != nil
+}
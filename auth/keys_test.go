@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	keys, err := NewKeys([]Key{
+		{Name: "alice", Key: "alice-key", DocBaseToken: "alice-token"},
+		{Name: "bob", Key: "bob-key"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+	}{
+		{name: "alice", authorization: "Bearer alice-key", want: "alice"},
+		{name: "bob", authorization: "Bearer bob-key", want: "bob"},
+		{name: "unknown key", authorization: "Bearer unknown"},
+		{name: "missing scheme", authorization: "alice-key"},
+		{name: "empty", authorization: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mcp", nil)
+			r.Header.Set("Authorization", tt.authorization)
+
+			key, ok := keys.Authenticate(r)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("Expected authentication to fail, but got %q", key.Name)
+				}
+				return
+			}
+			if !ok || key.Name != tt.want {
+				t.Errorf("Expected %q, but got %v (ok=%v)", tt.want, key, ok)
+			}
+		})
+	}
+}
+
+func TestNewKeysValidation(t *testing.T) {
+	if _, err := NewKeys(nil); err == nil {
+		t.Error("Expected an error for no keys")
+	}
+	if _, err := NewKeys([]Key{{Name: "empty"}}); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+	if _, err := NewKeys([]Key{{Name: "a", Key: "same"}, {Name: "b", Key: "same"}}); err == nil {
+		t.Error("Expected an error for duplicated keys")
+	}
+}
@@ -0,0 +1,554 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/scan"
+	"docbase-mcp-server/state"
+	"docbase-mcp-server/tools"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tokenCommandTimeout は token_command の実行を待つ時間です
+const tokenCommandTimeout = 10 * time.Second
+
+// Config は設定ファイルの内容を表します
+// 環境変数が設定されている項目は環境変数の値で上書きされます
+type Config struct {
+	// DefaultTeam は team 引数を省略したときに使うチームです
+	DefaultTeam string `yaml:"default_team"`
+	// Teams はチーム名ごとの接続設定です
+	Teams map[string]Team `yaml:"teams"`
+	// Policy はすべてのチームに共通の投稿のポリシーです。チームごとの policy で項目ごとに上書きできます
+	Policy Policy `yaml:"policy"`
+	// SecretScanning は投稿やコメントに含まれる認証情報の検査の設定です
+	SecretScanning SecretScanning `yaml:"secret_scanning"`
+	// PII は投稿やコメントに含まれる個人情報の、公開範囲ごとの扱いの設定です
+	PII PII `yaml:"pii"`
+	// Tools は公開するツールの設定です
+	Tools Tools `yaml:"tools"`
+	// Prompts はプロンプトの既定値です
+	Prompts Prompts `yaml:"prompts"`
+	// Subscriptions はリソースの購読の設定です
+	Subscriptions Subscriptions `yaml:"subscriptions"`
+	// Server はトランスポートの設定です。コマンドラインフラグで上書きできます
+	Server Server `yaml:"server"`
+	// ReadOnly が true の場合、DocBaseを変更するツールを公開せず、クライアントも GET 以外のリクエストを送りません
+	ReadOnly bool `yaml:"read_only"`
+	// DryRun が true の場合、書き込み系のツールは dry_run 引数に関係なく、送るはずのリクエストを返すだけでDocBaseを変更しません
+	DryRun bool `yaml:"dry_run"`
+	// StateDir は作成した投稿の記録など、サーバーの状態を保存するディレクトリです
+	// 省略した場合は $XDG_STATE_HOME/docbase-mcp-server を使います
+	StateDir string `yaml:"state_dir"`
+	// VerifyCredentials が true の場合、起動時に各チームのAPIトークンを1回のAPI呼び出しで確認します
+	VerifyCredentials bool `yaml:"verify_credentials"`
+}
+
+// Team はチームの接続設定を表します
+// APIトークンは token、token_file、token_command のいずれか1つで指定します
+type Team struct {
+	Domain string `yaml:"domain"`
+	Token  string `yaml:"token"`
+	// TokenFile はAPIトークンを書いたファイルのパスです
+	TokenFile string `yaml:"token_file"`
+	// TokenCommand はAPIトークンを標準出力に書き出すコマンドです (例: パスワードマネージャのCLI)
+	TokenCommand string `yaml:"token_command"`
+	// Policy はこのチームの投稿のポリシーです
+	Policy Policy `yaml:"policy"`
+}
+
+// Policy はアシスタントが投稿するときの既定値と制限を表します
+// 省略した項目は共通のポリシー、それもなければ docbase.DefaultPolicy の値を使います
+type Policy struct {
+	// DefaultScope は scope を省略して投稿したときの公開範囲です
+	DefaultScope string `yaml:"default_scope"`
+	// DefaultNotice は notice を省略したときに通知するかです
+	DefaultNotice *bool `yaml:"default_notice"`
+	// AllowedScopes はアシスタントが使ってよい公開範囲です
+	AllowedScopes []string `yaml:"allowed_scopes"`
+}
+
+// apply は p で指定された項目を policy に上書きします
+func (p Policy) apply(policy docbase.Policy) (docbase.Policy, error) {
+	if p.DefaultScope != "" {
+		scope, err := docbase.ParseScope(p.DefaultScope)
+		if err != nil {
+			return policy, fmt.Errorf("default_scope: %w", err)
+		}
+		policy.DefaultScope = scope
+	}
+	if p.DefaultNotice != nil {
+		policy.DefaultNotice = *p.DefaultNotice
+	}
+	if p.AllowedScopes != nil {
+		policy.AllowedScopes = nil
+		for _, s := range p.AllowedScopes {
+			scope, err := docbase.ParseScope(s)
+			if err != nil {
+				return policy, fmt.Errorf("allowed_scopes: %w", err)
+			}
+			policy.AllowedScopes = append(policy.AllowedScopes, scope)
+		}
+	}
+	return policy, nil
+}
+
+// policy は team に適用するポリシーを返します
+func (c *Config) policy(team Team) (docbase.Policy, error) {
+	policy, err := c.Policy.apply(docbase.DefaultPolicy())
+	if err != nil {
+		return policy, err
+	}
+	policy, err = team.Policy.apply(policy)
+	if err != nil {
+		return policy, err
+	}
+	return policy, policy.Validate()
+}
+
+// SecretScanning は書き込み系のツールで本文を送る前に行う認証情報の検査の設定です
+type SecretScanning struct {
+	// Action は検出したときの扱い (block, redact, warn, allow) です。既定は block です
+	Action string `yaml:"action"`
+	// Entropy が false の場合、パターンに一致しないランダムな文字列は検出しません
+	Entropy *bool `yaml:"entropy"`
+	// Patterns は組み込みのパターンに加えて検出する正規表現です
+	Patterns []Pattern `yaml:"patterns"`
+}
+
+// Pattern は名前の付いた正規表現です
+type Pattern struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// SecretPolicy は認証情報の検査の設定から、ツールが使うポリシーを作成します
+func (c *Config) SecretPolicy() (tools.SecretPolicy, error) {
+	action := scan.ActionBlock
+	if c.SecretScanning.Action != "" {
+		var err error
+		action, err = scan.ParseAction(c.SecretScanning.Action)
+		if err != nil {
+			return tools.SecretPolicy{}, fmt.Errorf("secret_scanning.action: %w", err)
+		}
+	}
+
+	rules, err := compilePatterns("secret_scanning.patterns", c.SecretScanning.Patterns)
+	if err != nil {
+		return tools.SecretPolicy{}, err
+	}
+
+	entropy := c.SecretScanning.Entropy == nil || *c.SecretScanning.Entropy
+	return tools.SecretPolicy{
+		Action:  action,
+		Scanner: scan.NewSecretScanner(rules, entropy),
+	}, nil
+}
+
+// PII は公開範囲ごとの個人情報 (メールアドレス、電話番号、マイナンバー) の扱いの設定です
+// 省略した公開範囲は、everyone では redact、それ以外では allow として扱います
+type PII struct {
+	Private  string `yaml:"private"`
+	Group    string `yaml:"group"`
+	Everyone string `yaml:"everyone"`
+	// Patterns は組み込みのパターンに加えて個人情報として扱う正規表現です
+	Patterns []Pattern `yaml:"patterns"`
+}
+
+// PIIPolicy は個人情報の扱いの設定から、ツールが使うポリシーを作成します
+func (c *Config) PIIPolicy() (tools.PIIPolicy, error) {
+	actions := make(map[docbase.Scope]scan.Action)
+	for _, s := range []struct {
+		scope    docbase.Scope
+		value    string
+		fallback scan.Action
+	}{
+		{docbase.ScopePrivate, c.PII.Private, scan.ActionAllow},
+		{docbase.ScopeGroup, c.PII.Group, scan.ActionAllow},
+		{docbase.ScopeAll, c.PII.Everyone, scan.ActionRedact},
+	} {
+		action := s.fallback
+		if s.value != "" {
+			var err error
+			action, err = scan.ParseAction(s.value)
+			if err != nil {
+				return tools.PIIPolicy{}, fmt.Errorf("pii.%s: %w", s.scope, err)
+			}
+		}
+		actions[s.scope] = action
+	}
+
+	rules, err := compilePatterns("pii.patterns", c.PII.Patterns)
+	if err != nil {
+		return tools.PIIPolicy{}, err
+	}
+
+	return tools.PIIPolicy{
+		Actions: actions,
+		Scanner: scan.NewPIIScanner(rules),
+	}, nil
+}
+
+// compilePatterns は設定の正規表現をコンパイルします。key はエラーメッセージに使う設定の項目名です
+func compilePatterns(key string, patterns []Pattern) ([]scan.Rule, error) {
+	var rules []scan.Rule
+	for i, p := range patterns {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s[%d]: name is required", key, i)
+		}
+		rule, err := scan.NewRule(p.Name, p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type Tools struct {
+	// Enabled は公開するツールの名前です。空の場合はすべてのツールを公開します
+	Enabled []string `yaml:"enabled"`
+	// Disabled は公開しないツールの名前です。Enabled より優先します
+	Disabled []string `yaml:"disabled"`
+	// MaxBodyChars は get_post_by_post_id が一度に返す本文の最大文字数です。0の場合は既定値を使います
+	MaxBodyChars int `yaml:"max_body_chars"`
+}
+
+type Prompts struct {
+	// MinutesTemplatePostID は議事録のテンプレートとして使う投稿のIDです
+	MinutesTemplatePostID int64 `yaml:"minutes_template_post_id"`
+}
+
+type Subscriptions struct {
+	// PollInterval と RateLimitReserve は、指定されなければ既定値を使います
+	PollInterval     *time.Duration `yaml:"poll_interval"`
+	RateLimitReserve *int           `yaml:"rate_limit_reserve"`
+}
+
+type Server struct {
+	Transport string `yaml:"transport"`
+	Addr      string `yaml:"addr"`
+	BaseURL   string `yaml:"base_url"`
+	AuthKeys  string `yaml:"auth_keys"`
+	NoAuth    bool   `yaml:"no_auth"`
+}
+
+// DefaultPath は設定ファイルの既定のパス ($XDG_CONFIG_HOME/docbase-mcp-server/config.yaml) を返します
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "docbase-mcp-server", "config.yaml")
+}
+
+// Load は path の設定ファイルを読み込み、環境変数で上書きします
+// path が空の場合は DefaultPath を使い、そのファイルが存在しなければ環境変数だけで設定を作ります
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	cfg := &Config{}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			dec := yaml.NewDecoder(bytes.NewReader(b))
+			dec.KnownFields(true)
+			if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		case errors.Is(err, os.ErrNotExist) && !explicit:
+			// 既定のパスに設定ファイルがないのは正常
+		default:
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if cfg.StateDir == "" {
+		cfg.StateDir = state.DefaultDir()
+	}
+	stateDir, err := expandHome(cfg.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StateDir = stateDir
+
+	return cfg, nil
+}
+
+// applyEnv は環境変数の値で設定を上書きします
+func (c *Config) applyEnv() error {
+	if c.Teams == nil {
+		c.Teams = make(map[string]Team)
+	}
+
+	// DOCBASE_API_DOMAIN と DOCBASE_API_TOKEN はドメインを名前とするチームになる
+	if domain := os.Getenv("DOCBASE_API_DOMAIN"); domain != "" {
+		team := c.Teams[domain]
+		team.Domain = domain
+		overrideToken(&team, os.Getenv("DOCBASE_API_TOKEN"))
+		c.Teams[domain] = team
+		// 1チームだけを使う従来の設定では、このチームを既定にする
+		if c.DefaultTeam == "" {
+			c.DefaultTeam = domain
+		}
+	}
+
+	// DOCBASE_TEAMS に並べたチームは DOCBASE_TEAM_<NAME>_DOMAIN と DOCBASE_TEAM_<NAME>_TOKEN で設定する
+	for _, name := range strings.Split(os.Getenv("DOCBASE_TEAMS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "DOCBASE_TEAM_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		team := c.Teams[name]
+		if domain := os.Getenv(prefix + "_DOMAIN"); domain != "" {
+			team.Domain = domain
+		}
+		overrideToken(&team, os.Getenv(prefix+"_TOKEN"))
+		c.Teams[name] = team
+	}
+
+	if v := os.Getenv("DOCBASE_DEFAULT_TEAM"); v != "" {
+		c.DefaultTeam = v
+	}
+
+	if v := os.Getenv("DOCBASE_READ_ONLY"); v != "" {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DOCBASE_READ_ONLY: %q", v)
+		}
+		c.ReadOnly = readOnly
+	}
+
+	if v := os.Getenv("DOCBASE_DRY_RUN"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DOCBASE_DRY_RUN: %q", v)
+		}
+		c.DryRun = dryRun
+	}
+
+	if v := os.Getenv("DOCBASE_MAX_BODY_CHARS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid DOCBASE_MAX_BODY_CHARS: %q", v)
+		}
+		c.Tools.MaxBodyChars = n
+	}
+
+	if v := os.Getenv("DOCBASE_STATE_DIR"); v != "" {
+		c.StateDir = v
+	}
+
+	if v := os.Getenv("DOCBASE_MINUTES_TEMPLATE_POST_ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid DOCBASE_MINUTES_TEMPLATE_POST_ID: %q", v)
+		}
+		c.Prompts.MinutesTemplatePostID = id
+	}
+
+	if v := os.Getenv("DOCBASE_POLL_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid DOCBASE_POLL_INTERVAL: %q", v)
+		}
+		c.Subscriptions.PollInterval = &interval
+	}
+
+	if v := os.Getenv("DOCBASE_POLL_RATE_LIMIT_RESERVE"); v != "" {
+		reserve, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid DOCBASE_POLL_RATE_LIMIT_RESERVE: %q", v)
+		}
+		c.Subscriptions.RateLimitReserve = &reserve
+	}
+
+	return nil
+}
+
+// overrideToken は環境変数で指定されたトークンを、設定ファイルのトークンの指定より優先させます
+func overrideToken(team *Team, token string) {
+	if token == "" {
+		return
+	}
+	team.Token = token
+	team.TokenFile = ""
+	team.TokenCommand = ""
+}
+
+// Validate は設定の誤りをまとめて返します
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Teams) == 0 {
+		errs = append(errs, errors.New("no DocBase team is configured: set DOCBASE_API_DOMAIN and DOCBASE_API_TOKEN, or add teams to the config file"))
+	}
+
+	// ユーザーごとのトークンを使う場合はチームで共有するトークンを省略できる
+	sharedTokenRequired := c.Server.AuthKeys == ""
+
+	for _, name := range c.teamNames() {
+		team := c.Teams[name]
+		if team.Domain == "" {
+			errs = append(errs, fmt.Errorf("teams.%s: domain is required", name))
+		}
+
+		if _, err := c.policy(team); err != nil {
+			errs = append(errs, fmt.Errorf("teams.%s.policy: %w", name, err))
+		}
+
+		sources := 0
+		for _, v := range []string{team.Token, team.TokenFile, team.TokenCommand} {
+			if v != "" {
+				sources++
+			}
+		}
+		switch {
+		case sources > 1:
+			errs = append(errs, fmt.Errorf("teams.%s: only one of token, token_file and token_command can be set", name))
+		case sources == 0 && sharedTokenRequired:
+			errs = append(errs, fmt.Errorf("teams.%s: an API token is required (token, token_file or token_command)", name))
+		}
+	}
+
+	if c.DefaultTeam != "" {
+		if _, ok := c.Teams[c.DefaultTeam]; !ok {
+			errs = append(errs, fmt.Errorf("default_team: team %q is not configured", c.DefaultTeam))
+		}
+	}
+
+	if c.Tools.MaxBodyChars < 0 {
+		errs = append(errs, fmt.Errorf("tools.max_body_chars: must not be negative (got %d)", c.Tools.MaxBodyChars))
+	}
+
+	if c.Subscriptions.PollInterval != nil && *c.Subscriptions.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("subscriptions.poll_interval must be positive (got %s)", *c.Subscriptions.PollInterval))
+	}
+	if c.Subscriptions.RateLimitReserve != nil && *c.Subscriptions.RateLimitReserve < 0 {
+		errs = append(errs, errors.New("subscriptions.rate_limit_reserve must not be negative"))
+	}
+
+	if _, err := c.SecretPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.PIIPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Server.Transport {
+	case "", "stdio", "sse", "http":
+	default:
+		errs = append(errs, fmt.Errorf("server.transport: unknown transport %q (must be stdio, sse or http)", c.Server.Transport))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ResolveTeams はAPIトークンを解決してチームの設定を作成します
+func (c *Config) ResolveTeams(ctx context.Context) (*docbase.Teams, error) {
+	var teams []docbase.Team
+	for _, name := range c.teamNames() {
+		team := c.Teams[name]
+		token, err := team.resolveToken(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("teams.%s: %w", name, err)
+		}
+		policy, err := c.policy(team)
+		if err != nil {
+			return nil, fmt.Errorf("teams.%s.policy: %w", name, err)
+		}
+		teams = append(teams, docbase.Team{
+			Name:     name,
+			Domain:   team.Domain,
+			APIToken: token,
+			Policy:   policy,
+		})
+	}
+
+	return docbase.NewTeams(teams, c.DefaultTeam)
+}
+
+// teamNames はチーム名を名前順に返します
+func (c *Config) teamNames() []string {
+	names := make([]string, 0, len(c.Teams))
+	for name := range c.Teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandHome は ~/ で始まるパスをホームディレクトリからのパスにします
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+func (t Team) resolveToken(ctx context.Context) (string, error) {
+	switch {
+	case t.TokenFile != "":
+		path, err := expandHome(t.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token_file: %w", err)
+		}
+		return nonEmptyToken(string(b), "token_file")
+	case t.TokenCommand != "":
+		ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+		defer cancel()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", t.TokenCommand)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("token_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nonEmptyToken(string(out), "token_command")
+	default:
+		return t.Token, nil
+	}
+}
+
+func nonEmptyToken(s, source string) (string, error) {
+	token := strings.TrimSpace(s)
+	if token == "" {
+		return "", fmt.Errorf("%s returned an empty token", source)
+	}
+	return token, nil
+}
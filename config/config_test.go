@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"DOCBASE_API_DOMAIN", "DOCBASE_API_TOKEN", "DOCBASE_TEAMS", "DOCBASE_DEFAULT_TEAM", "DOCBASE_READ_ONLY", "DOCBASE_DRY_RUN", "DOCBASE_STATE_DIR", "DOCBASE_MAX_BODY_CHARS",
+		"DOCBASE_MINUTES_TEMPLATE_POST_ID", "DOCBASE_POLL_INTERVAL", "DOCBASE_POLL_RATE_LIMIT_RESERVE",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	path := writeConfig(t, `
+default_team: prod
+policy:
+  allowed_scopes: [private, group]
+teams:
+  prod:
+    domain: example
+    token_file: `+tokenFile+`
+    policy:
+      default_notice: true
+  sandbox:
+    domain: example-sandbox
+    token_command: echo command-token
+subscriptions:
+  poll_interval: 30s
+`)
+
+	// 環境変数は設定ファイルより優先する
+	t.Setenv("DOCBASE_TEAMS", "sandbox")
+	t.Setenv("DOCBASE_TEAM_SANDBOX_TOKEN", "env-token")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+	if cfg.Subscriptions.PollInterval == nil || *cfg.Subscriptions.PollInterval != 30*time.Second {
+		t.Errorf("Expected poll interval to be 30s, but got %v", cfg.Subscriptions.PollInterval)
+	}
+
+	teams, err := cfg.ResolveTeams(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if teams.Default() != "prod" {
+		t.Errorf("Expected default team to be %q, but got %q", "prod", teams.Default())
+	}
+
+	for name, want := range map[string]string{"prod": "file-token", "sandbox": "env-token"} {
+		team, err := teams.Get(name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if team.APIToken != want {
+			t.Errorf("Expected the token of %s to be %q, but got %q", name, want, team.APIToken)
+		}
+	}
+
+	// チームのポリシーは共通のポリシーを項目ごとに上書きする
+	prod, _ := teams.Get("prod")
+	sandbox, _ := teams.Get("sandbox")
+	if !prod.Policy.DefaultNotice || sandbox.Policy.DefaultNotice {
+		t.Errorf("Expected only prod to notify by default, but got %+v and %+v", prod.Policy, sandbox.Policy)
+	}
+	if prod.Policy.Allows(docbase.ScopeAll) || sandbox.Policy.Allows(docbase.ScopeAll) {
+		t.Error("Expected the shared policy to forbid 'everyone'")
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DOCBASE_TEAMS", "prod, sandbox-docs")
+	t.Setenv("DOCBASE_TEAM_PROD_DOMAIN", "example")
+	t.Setenv("DOCBASE_TEAM_PROD_TOKEN", "prod-token")
+	t.Setenv("DOCBASE_TEAM_SANDBOX_DOCS_DOMAIN", "example-sandbox")
+	t.Setenv("DOCBASE_TEAM_SANDBOX_DOCS_TOKEN", "sandbox-token")
+	t.Setenv("DOCBASE_DEFAULT_TEAM", "sandbox-docs")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	teams, err := cfg.ResolveTeams(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	team, err := teams.Get("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if team.Domain != "example-sandbox" || team.APIToken != "sandbox-token" {
+		t.Errorf("Unexpected default team: %+v", team)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	clearEnv(t)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing token",
+			content: "teams:\n  prod:\n    domain: example\n",
+			wantErr: "teams.prod: an API token is required",
+		},
+		{
+			name:    "zero poll interval",
+			content: "teams:\n  prod:\n    domain: example\n    token: a\nsubscriptions:\n  poll_interval: 0s\n",
+			wantErr: "subscriptions.poll_interval must be positive (got 0s)",
+		},
+		{
+			name:    "negative body limit",
+			content: "teams:\n  prod:\n    domain: example\n    token: a\ntools:\n  max_body_chars: -1\n",
+			wantErr: "tools.max_body_chars: must not be negative",
+		},
+		{
+			name:    "several token sources",
+			content: "teams:\n  prod:\n    domain: example\n    token: a\n    token_file: b\n",
+			wantErr: "only one of token, token_file and token_command",
+		},
+		{
+			name:    "unknown default team",
+			content: "default_team: dev\nteams:\n  prod:\n    domain: example\n    token: a\n",
+			wantErr: `default_team: team "dev" is not configured`,
+		},
+		{
+			name:    "default scope not allowed",
+			content: "policy:\n  default_scope: everyone\n  allowed_scopes: [private]\nteams:\n  prod:\n    domain: example\n    token: a\n",
+			wantErr: "teams.prod.policy: default scope \"everyone\" is not one of the allowed scopes",
+		},
+		{
+			name:    "no teams",
+			content: "",
+			wantErr: "no DocBase team is configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			err = cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// 知らない項目は書き間違いとして扱う
+	if _, err := Load(writeConfig(t, "teams:\n  prod:\n    domian: example\n")); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+
+	// 明示したファイルが存在しない場合はエラーにする
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
@@ -4,22 +4,29 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 )
 
 type GetPostResponse struct {
-	PostID    int64     `json:"id"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	Draft     bool      `json:"draft"`
-	Archived  bool      `json:"archived"`
-	Tags      []Tag     `json:"tags"`
-	User      User      `json:"user"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	PostID    int64             `json:"id"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Draft     bool              `json:"draft"`
+	Archived  bool              `json:"archived"`
+	URL       string            `json:"url"`
+	Scope     Scope             `json:"scope"`
+	Groups    []Group           `json:"groups"`
+	Tags      []Tag             `json:"tags"`
+	User      User              `json:"user"`
+	Comments  []CommentResponse `json:"comments"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
 }
 
 type User struct {
@@ -68,11 +75,26 @@ const (
 	ScopePrivate Scope = "private"
 )
 
+// ErrReadOnly は読み取り専用のクライアントで投稿などを変更しようとしたときに返されます
+var ErrReadOnly = errors.New("the server is in read-only mode and cannot modify DocBase")
+
+// RateLimit はレスポンスヘッダから読み取ったDocBase APIのレート制限の状態を表します
+type RateLimit struct {
+	Limit     int       // 期間内に許可されるリクエスト数
+	Remaining int       // 期間内の残りリクエスト数
+	Reset     time.Time // 残りリクエスト数がリセットされる時刻
+}
+
 type DocBaseClient struct {
 	Client   *http.Client
 	Domain   string
 	APIToken string
 	BaseURL  string
+	// ReadOnly が true の場合、GET 以外のリクエストは送信せずに ErrReadOnly を返します
+	ReadOnly bool
+
+	mu        sync.Mutex
+	rateLimit RateLimit
 }
 
 func NewDocBaseClient(domain, apiToken string) *DocBaseClient {
@@ -84,11 +106,18 @@ func NewDocBaseClient(domain, apiToken string) *DocBaseClient {
 	}
 }
 
-func (c *DocBaseClient) GetPost(ctx context.Context, postID int64) (*GetPostResponse, error) {
-	url := fmt.Sprintf("%s/posts/%d", c.BaseURL, postID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// RateLimit は直近のレスポンスで観測したレート制限の状態を返します
+// まだリクエストを送っていない場合はゼロ値を返します
+func (c *DocBaseClient) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// do は認証ヘッダを付けてリクエストを送信し、レート制限の状態を記録します
+func (c *DocBaseClient) do(req *http.Request) (*http.Response, error) {
+	if c.ReadOnly && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, ErrReadOnly
 	}
 
 	req.Header.Set("X-DocBaseToken", c.APIToken)
@@ -98,10 +127,50 @@ func (c *DocBaseClient) GetPost(ctx context.Context, postID int64) (*GetPostResp
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+
+	c.recordRateLimit(resp.Header)
+
+	return resp, nil
+}
+
+func (c *DocBaseClient) recordRateLimit(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}
+}
+
+func (c *DocBaseClient) GetPost(ctx context.Context, postID int64) (*GetPostResponse, error) {
+	url := fmt.Sprintf("%s/posts/%d", c.BaseURL, postID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var post GetPostResponse
@@ -140,17 +209,14 @@ func (c *DocBaseClient) SearchPosts(ctx context.Context, query SearchQuery) (*Se
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-DocBaseToken", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var searchResp SearchPostsResponse
@@ -174,17 +240,14 @@ func (c *DocBaseClient) CreatePost(ctx context.Context, param CreatePostParam) (
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-DocBaseToken", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var post GetPostResponse
@@ -196,13 +259,14 @@ func (c *DocBaseClient) CreatePost(ctx context.Context, param CreatePostParam) (
 }
 
 type UpdatePostParam struct {
-	Title  string   `json:"title,omitempty"`
-	Body   string   `json:"body,omitempty"`
-	Draft  *bool    `json:"draft,omitempty"`
-	Notice *bool    `json:"notice,omitempty"`
-	Tags   []string `json:"tags,omitempty"`
-	Scope  Scope    `json:"scope,omitempty"`
-	Groups []int    `json:"groups,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body,omitempty"`
+	Draft  *bool  `json:"draft,omitempty"`
+	Notice *bool  `json:"notice,omitempty"`
+	// Tags は nil の場合は変更せず、空のリストの場合はタグをすべて外します
+	Tags   *[]string `json:"tags,omitempty"`
+	Scope  Scope     `json:"scope,omitempty"`
+	Groups []int     `json:"groups,omitempty"`
 }
 
 // UpdatePost はDocBase APIを使用して既存の投稿を更新します
@@ -220,17 +284,14 @@ func (c *DocBaseClient) UpdatePost(ctx context.Context, postID int64, param Upda
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-DocBaseToken", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var post GetPostResponse
@@ -270,17 +331,14 @@ func (c *DocBaseClient) CreateComment(ctx context.Context, postID int64, param C
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-DocBaseToken", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var comment CommentResponse
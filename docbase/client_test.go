@@ -1,7 +1,14 @@
 package docbase
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewDocBaseClient(t *testing.T) {
@@ -26,3 +33,123 @@ func TestNewDocBaseClient(t *testing.T) {
 		t.Error("Expected http.Client to be initialized, but it's nil")
 	}
 }
+
+func TestRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "300")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Write([]byte(`{"id": 1, "title": "test"}`))
+	}))
+	defer ts.Close()
+
+	client := NewDocBaseClient("example", "test-token")
+	client.BaseURL = ts.URL
+
+	if rl := client.RateLimit(); rl.Limit != 0 {
+		t.Errorf("Expected zero rate limit before any request, but got %+v", rl)
+	}
+
+	if _, err := client.GetPost(context.Background(), 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rl := client.RateLimit()
+	if rl.Limit != 300 || rl.Remaining != 42 || !rl.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected rate limit: %+v", rl)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	var methods []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Write([]byte(`{"id": 1, "title": "test"}`))
+	}))
+	defer ts.Close()
+
+	client := NewDocBaseClient("example", "test-token")
+	client.BaseURL = ts.URL
+	client.ReadOnly = true
+
+	if _, err := client.GetPost(context.Background(), 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := client.UpdatePost(context.Background(), 1, UpdatePostParam{Title: "changed"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, but got %v", err)
+	}
+	if _, err := client.CreatePost(context.Background(), CreatePostParam{Title: "new"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, but got %v", err)
+	}
+
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Errorf("Expected only the GET request to be sent, but got %v", methods)
+	}
+}
+
+func TestGroupCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("page") == "1" {
+			groups := make([]Group, 0, 100)
+			for i := 1; i <= 100; i++ {
+				groups = append(groups, Group{ID: i, Name: fmt.Sprintf("group-%d", i)})
+			}
+			json.NewEncoder(w).Encode(groups)
+			return
+		}
+		w.Write([]byte(`[{"id": 101, "name": "backend-team"}]`))
+	}))
+	defer ts.Close()
+
+	client := NewDocBaseClient("example", "test-token")
+	client.BaseURL = ts.URL
+	cache := NewGroupCache(time.Hour)
+
+	groups, err := cache.Groups(context.Background(), client, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(groups) != 101 || groups[100].Name != "backend-team" {
+		t.Errorf("Expected every page of groups, but got %d groups", len(groups))
+	}
+
+	if _, err := cache.Groups(context.Background(), client, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the cached groups to be used, but got %d requests", requests)
+	}
+
+	if _, err := cache.Groups(context.Background(), client, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("Expected the groups to be fetched again, but got %d requests", requests)
+	}
+}
+
+func TestUpdatePostParamTags(t *testing.T) {
+	empty := []string{}
+	tests := []struct {
+		name  string
+		param UpdatePostParam
+		want  string
+	}{
+		{"unchanged", UpdatePostParam{Title: "t"}, `{"title":"t"}`},
+		{"cleared", UpdatePostParam{Tags: &empty}, `{"tags":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.param)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("Expected %s, but got %s", tt.want, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package docbase
+
+import (
+	"context"
+	"fmt"
+)
+
+// UserTokens はHTTPトランスポートで接続してきたユーザー自身のAPIトークンを表します
+type UserTokens struct {
+	Default string            // 既定のチームで使うトークン
+	Teams   map[string]string // チーム名ごとのトークン。Default より優先します
+}
+
+type userTokensKey struct{}
+
+// WithUserTokens は ctx にユーザーのAPIトークンを設定します
+// 設定された ctx では、チームで共有するトークンは使わずユーザーのトークンだけでAPIを呼びます
+func WithUserTokens(ctx context.Context, tokens UserTokens) context.Context {
+	return context.WithValue(ctx, userTokensKey{}, tokens)
+}
+
+// NewClient は SetTeams で登録された設定から team のチームに対するクライアントを作成します
+// team が空の場合は既定のチームを使います
+func NewClient(ctx context.Context, team string) (*DocBaseClient, error) {
+	teams, err := ConfiguredTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := teams.Get(team)
+	if err != nil {
+		return nil, err
+	}
+
+	token := t.APIToken
+	if tokens, ok := ctx.Value(userTokensKey{}).(UserTokens); ok {
+		token = tokens.Teams[t.Name]
+		if token == "" && t.Name == teams.Default() {
+			token = tokens.Default
+		}
+		if token == "" {
+			return nil, fmt.Errorf("no DocBase API token is configured for team %q for this client", t.Name)
+		}
+	}
+
+	client := NewDocBaseClient(t.Domain, token)
+	client.ReadOnly = IsReadOnly()
+	return client, nil
+}
@@ -0,0 +1,42 @@
+package docbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize はエラーレスポンスから読み取る本文の上限です
+const maxErrorBodySize = 64 << 10
+
+// APIError はDocBase APIが成功以外のステータスコードを返したことを表します
+type APIError struct {
+	StatusCode int
+	// Messages はレスポンスの messages に含まれていたエラーメッセージです
+	Messages []string
+}
+
+func (e *APIError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, strings.Join(e.Messages, ", "))
+}
+
+// newAPIError はエラーレスポンスの本文を読み取って APIError を作成します
+//
+//	{"error": "bad_request", "messages": ["タイトルを入力してください"]}
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var body struct {
+		Messages []string `json:"messages"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body); err == nil {
+		apiErr.Messages = body.Messages
+	}
+
+	return apiErr
+}
@@ -0,0 +1,105 @@
+package docbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// groupsPerPage はグループ一覧APIの1ページあたりの件数 (APIの上限) です
+const groupsPerPage = 100
+
+// Group はDocBaseのグループを表します
+type Group struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListGroups はチームのすべてのグループを返します
+// GET /teams/:domain/groups
+func (c *DocBaseClient) ListGroups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/groups?page=%d&per_page=%d", c.BaseURL, page, groupsPerPage)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(resp)
+			resp.Body.Close()
+			return nil, apiErr
+		}
+
+		var pageGroups []Group
+		err = json.NewDecoder(resp.Body).Decode(&pageGroups)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		groups = append(groups, pageGroups...)
+		if len(pageGroups) < groupsPerPage {
+			return groups, nil
+		}
+	}
+}
+
+// GroupCache はグループの一覧をチームとAPIトークンごとに一定時間キャッシュします
+// トークンによって見えるグループが異なる可能性があるため、トークンもキーに含めます
+type GroupCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[groupCacheKey]groupCacheEntry
+}
+
+type groupCacheKey struct {
+	domain, token string
+}
+
+type groupCacheEntry struct {
+	groups    []Group
+	fetchedAt time.Time
+}
+
+// NewGroupCache は ttl の間グループの一覧をキャッシュする GroupCache を作成します
+func NewGroupCache(ttl time.Duration) *GroupCache {
+	return &GroupCache{
+		TTL:     ttl,
+		entries: make(map[groupCacheKey]groupCacheEntry),
+	}
+}
+
+// Groups は client のチームのグループの一覧を返します
+// キャッシュが古い場合、または refresh が true の場合はAPIから取得し直します
+func (gc *GroupCache) Groups(ctx context.Context, client *DocBaseClient, refresh bool) ([]Group, error) {
+	key := groupCacheKey{domain: client.Domain, token: client.APIToken}
+
+	gc.mu.Lock()
+	entry, ok := gc.entries[key]
+	gc.mu.Unlock()
+	if ok && !refresh && time.Since(entry.fetchedAt) < gc.TTL {
+		return entry.groups, nil
+	}
+
+	groups, err := client.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gc.mu.Lock()
+	gc.entries[key] = groupCacheEntry{groups: groups, fetchedAt: time.Now()}
+	gc.mu.Unlock()
+
+	return groups, nil
+}
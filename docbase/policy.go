@@ -0,0 +1,90 @@
+package docbase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scopes は投稿の公開範囲を狭い順に並べたものです
+var Scopes = []Scope{ScopePrivate, ScopeGroup, ScopeAll}
+
+// ParseScope は公開範囲の名前を Scope に変換します
+func ParseScope(s string) (Scope, error) {
+	for _, scope := range Scopes {
+		if string(scope) == s {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope %q (must be one of %s)", s, joinScopes(Scopes))
+}
+
+// Policy はアシスタントが投稿するときの既定値と制限を表します
+type Policy struct {
+	// DefaultScope は scope を省略したときの公開範囲です
+	DefaultScope Scope
+	// DefaultNotice は notice を省略したときに通知するかです
+	DefaultNotice bool
+	// AllowedScopes はアシスタントが使ってよい公開範囲です
+	AllowedScopes []Scope
+}
+
+// DefaultPolicy はポリシーが設定されていないチームに使うポリシーです
+// 誤って公開しないよう、非公開で通知しないことを既定にします
+func DefaultPolicy() Policy {
+	return Policy{
+		DefaultScope:  ScopePrivate,
+		DefaultNotice: false,
+		AllowedScopes: append([]Scope(nil), Scopes...),
+	}
+}
+
+// Allows は scope の使用が許可されているかを返します
+func (p Policy) Allows(scope Scope) bool {
+	for _, s := range p.AllowedScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckScope は scope が許可されていない場合に、許可されている公開範囲を含むエラーを返します
+func (p Policy) CheckScope(scope Scope) error {
+	if p.Allows(scope) {
+		return nil
+	}
+	return fmt.Errorf("scope %q is not allowed by the posting policy of this team (allowed scopes: %s)", scope, joinScopes(p.AllowedScopes))
+}
+
+// Validate はポリシーの設定に矛盾がないかを確認します
+func (p Policy) Validate() error {
+	if len(p.AllowedScopes) == 0 {
+		return fmt.Errorf("at least one scope must be allowed")
+	}
+	if !p.Allows(p.DefaultScope) {
+		return fmt.Errorf("default scope %q is not one of the allowed scopes (%s)", p.DefaultScope, joinScopes(p.AllowedScopes))
+	}
+	return nil
+}
+
+func joinScopes(scopes []Scope) string {
+	names := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		names = append(names, "'"+string(s)+"'")
+	}
+	return strings.Join(names, ", ")
+}
+
+// Widens は公開範囲を from から to に変えると、読める人が増えるかを返します
+func Widens(from, to Scope) bool {
+	return scopeRank(to) > scopeRank(from)
+}
+
+func scopeRank(scope Scope) int {
+	for i, s := range Scopes {
+		if s == scope {
+			return i
+		}
+	}
+	return -1
+}
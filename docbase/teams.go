@@ -0,0 +1,131 @@
+package docbase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Team はDocBaseのチームへの接続設定を表します
+type Team struct {
+	Name     string // ツールの team 引数で指定する名前
+	Domain   string // DocBaseのチームのドメイン
+	APIToken string // チームで共有するAPIトークン
+	Policy   Policy // アシスタントが投稿するときのポリシー
+}
+
+// Teams は設定されたチームの一覧と既定のチームを表します
+type Teams struct {
+	teams       map[string]Team
+	defaultTeam string
+}
+
+// NewTeams は teams を検証して Teams を作成します
+// defaultTeam が空の場合は最初のチームを既定にします
+func NewTeams(teams []Team, defaultTeam string) (*Teams, error) {
+	if len(teams) == 0 {
+		return nil, errors.New("at least one DocBase team must be configured")
+	}
+
+	t := &Teams{teams: make(map[string]Team, len(teams))}
+	for _, team := range teams {
+		if team.Name == "" {
+			return nil, errors.New("team name must not be empty")
+		}
+		if team.Domain == "" {
+			return nil, fmt.Errorf("domain is not configured for team %q", team.Name)
+		}
+		if _, ok := t.teams[team.Name]; ok {
+			return nil, fmt.Errorf("team %q is configured more than once", team.Name)
+		}
+		if team.Policy.DefaultScope == "" {
+			team.Policy = DefaultPolicy()
+		}
+		if err := team.Policy.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid posting policy for team %q: %w", team.Name, err)
+		}
+		t.teams[team.Name] = team
+	}
+
+	if defaultTeam == "" {
+		defaultTeam = teams[0].Name
+	}
+	if _, ok := t.teams[defaultTeam]; !ok {
+		return nil, fmt.Errorf("default team %q is not configured", defaultTeam)
+	}
+	t.defaultTeam = defaultTeam
+
+	return t, nil
+}
+
+// Default は既定のチームの名前を返します
+func (t *Teams) Default() string {
+	return t.defaultTeam
+}
+
+// List は名前順にチームの一覧を返します
+func (t *Teams) List() []Team {
+	teams := make([]Team, 0, len(t.teams))
+	for _, team := range t.teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+// Get は name のチームを返します。name が空の場合は既定のチームを返します
+func (t *Teams) Get(name string) (Team, error) {
+	if name == "" {
+		name = t.defaultTeam
+	}
+
+	team, ok := t.teams[name]
+	if !ok {
+		names := make([]string, 0, len(t.teams))
+		for _, team := range t.List() {
+			names = append(names, team.Name)
+		}
+		return Team{}, fmt.Errorf("unknown team %q (configured teams: %s)", name, strings.Join(names, ", "))
+	}
+	return team, nil
+}
+
+var (
+	configuredMu sync.RWMutex
+	configured   *Teams
+	readOnly     bool
+)
+
+// SetTeams はツールなどが NewClient で使うチームの設定を登録します
+// 起動時に一度呼び出します
+func SetTeams(teams *Teams) {
+	configuredMu.Lock()
+	defer configuredMu.Unlock()
+	configured = teams
+}
+
+// ConfiguredTeams は SetTeams で登録されたチームの設定を返します
+func ConfiguredTeams() (*Teams, error) {
+	configuredMu.RLock()
+	defer configuredMu.RUnlock()
+	if configured == nil {
+		return nil, errors.New("no DocBase team is configured")
+	}
+	return configured, nil
+}
+
+// SetReadOnly は NewClient で作成するクライアントを読み取り専用にするかを設定します
+func SetReadOnly(v bool) {
+	configuredMu.Lock()
+	defer configuredMu.Unlock()
+	readOnly = v
+}
+
+// IsReadOnly は読み取り専用モードかを返します
+func IsReadOnly() bool {
+	configuredMu.RLock()
+	defer configuredMu.RUnlock()
+	return readOnly
+}
@@ -0,0 +1,40 @@
+package docbase
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewClient(t *testing.T) {
+	teams, err := NewTeams([]Team{
+		{Name: "prod", Domain: "example", APIToken: "shared-prod"},
+		{Name: "sandbox", Domain: "example-sandbox", APIToken: "shared-sandbox"},
+	}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	SetTeams(teams)
+	defer SetTeams(nil)
+
+	client, err := NewClient(context.Background(), "sandbox")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.Domain != "example-sandbox" || client.APIToken != "shared-sandbox" {
+		t.Errorf("Expected the shared sandbox token, but got %q for %q", client.APIToken, client.Domain)
+	}
+
+	// ユーザーのトークンが設定されている場合、共有のトークンは使わない
+	ctx := WithUserTokens(context.Background(), UserTokens{Default: "alice-prod"})
+	client, err = NewClient(ctx, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.APIToken != "alice-prod" {
+		t.Errorf("Expected the user's token, but got %q", client.APIToken)
+	}
+
+	if _, err := NewClient(ctx, "sandbox"); err == nil {
+		t.Error("Expected an error for a team without the user's token")
+	}
+}
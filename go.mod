@@ -4,9 +4,10 @@ go 1.23
 
 toolchain go1.23.7
 
-require github.com/mark3labs/mcp-go v0.15.0
-
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/mark3labs/mcp-go v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
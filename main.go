@@ -1,29 +1,208 @@
 package main
 
 import (
+	"context"
+	"docbase-mcp-server/auth"
+	"docbase-mcp-server/config"
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/prompts"
+	"docbase-mcp-server/resources"
 	"docbase-mcp-server/tools"
+	"docbase-mcp-server/transport"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// verifyTimeout は起動時にAPIトークンを確認するAPI呼び出しを待つ時間です
+const verifyTimeout = 10 * time.Second
+
 func main() {
+	configPath := flag.String("config", "", "Path to the config file (default is $XDG_CONFIG_HOME/docbase-mcp-server/config.yaml)")
+	transportFlag := flag.String("transport", "stdio", "Transport to serve MCP over: stdio, sse or http")
+	addr := flag.String("addr", ":8080", "Address to listen on for the sse and http transports")
+	baseURL := flag.String("base-url", "", "Public base URL advertised to sse clients (default is a relative path)")
+	authKeys := flag.String("auth-keys", "", "JSON file with the bearer keys allowed to use the sse and http transports")
+	noAuth := flag.Bool("no-auth", false, "Serve the sse and http transports without authentication (only for local development)")
+	readOnly := flag.Bool("read-only", false, "Only expose the tools that read DocBase, and refuse every request that modifies it")
+	dryRun := flag.Bool("dry-run", false, "Never modify DocBase: tools that write only return the request they would send")
+	verify := flag.Bool("verify", false, "Verify the API token of each team with one API call before serving")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// コマンドラインフラグは設定ファイルと環境変数より優先する
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "transport":
+			cfg.Server.Transport = *transportFlag
+		case "addr":
+			cfg.Server.Addr = *addr
+		case "base-url":
+			cfg.Server.BaseURL = *baseURL
+		case "auth-keys":
+			cfg.Server.AuthKeys = *authKeys
+		case "no-auth":
+			cfg.Server.NoAuth = *noAuth
+		case "read-only":
+			cfg.ReadOnly = *readOnly
+		case "dry-run":
+			cfg.DryRun = *dryRun
+		case "verify":
+			cfg.VerifyCredentials = *verify
+		}
+	})
+	if cfg.Server.Transport == "" {
+		cfg.Server.Transport = *transportFlag
+	}
+	if cfg.Server.Addr == "" {
+		cfg.Server.Addr = *addr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	teams, err := cfg.ResolveTeams(ctx)
+	if err != nil {
+		log.Fatalf("Invalid DocBase team configuration: %v", err)
+	}
+	docbase.SetTeams(teams)
+	docbase.SetReadOnly(cfg.ReadOnly)
+
+	if cfg.VerifyCredentials {
+		if err := verifyCredentials(ctx, teams); err != nil {
+			log.Fatalf("Failed to verify DocBase credentials: %v", err)
+		}
+	}
+
 	s := server.NewMCPServer(
 		"docbase-mcp-server",
 		"0.0.1",
 		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(false),
 		server.WithLogging(),
 	)
 
-	s.AddTools(
+	secretPolicy, err := cfg.SecretPolicy()
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	tools.SetSecretPolicy(secretPolicy)
+	piiPolicy, err := cfg.PIIPolicy()
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	tools.SetPIIPolicy(piiPolicy)
+	tools.SetDryRun(cfg.DryRun)
+	tools.SetStateDir(cfg.StateDir)
+	if cfg.Tools.MaxBodyChars > 0 {
+		tools.SetMaxBodyChars(cfg.Tools.MaxBodyChars)
+	}
+
+	// 無効なツールは登録せず、tools/list にも出さない
+	enabledTools, err := tools.Select([]server.ServerTool{
 		tools.NewCreatePostTool(),
 		tools.NewGetPostTool(),
 		tools.NewSearchPostsTool(),
 		tools.NewUpdatePostTool(),
+		tools.NewPatchPostTool(),
+		tools.NewReplaceInPostsTool(),
+		tools.NewEditPostSectionTool(),
 		tools.NewCreateCommentTool(),
-	)
+		tools.NewListTeamsTool(),
+		tools.NewListPostHistoryTool(),
+		tools.NewDiffPostVersionsTool(),
+		tools.NewRestorePostVersionTool(),
+	}, tools.Filter{
+		Enabled:  cfg.Tools.Enabled,
+		Disabled: cfg.Tools.Disabled,
+		ReadOnly: cfg.ReadOnly,
+	})
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	s.AddTools(enabledTools...)
+
+	for _, p := range []prompts.ServerPrompt{
+		prompts.NewSummarizePostPrompt(),
+		prompts.NewMeetingMinutesPrompt(cfg.Prompts.MinutesTemplatePostID),
+		prompts.NewWeeklyReportPrompt(),
+		prompts.NewReviewPostPrompt(),
+	} {
+		s.AddPrompt(p.Prompt, p.Handler)
+	}
+
+	s.AddResourceTemplate(resources.NewPostTemplate(), resources.HandleReadPost)
+
+	subscriptions := resources.NewSubscriptions(s)
+	if cfg.Subscriptions.PollInterval != nil {
+		subscriptions.Interval = *cfg.Subscriptions.PollInterval
+	}
+	if cfg.Subscriptions.RateLimitReserve != nil {
+		subscriptions.Reserve = *cfg.Subscriptions.RateLimitReserve
+	}
+
+	go subscriptions.Run(ctx)
 
-	if err := server.ServeStdio(s); err != nil {
+	opts := transport.Options{
+		Addr:          cfg.Server.Addr,
+		BaseURL:       cfg.Server.BaseURL,
+		SessionClosed: subscriptions.RemoveSession,
+	}
+	if cfg.Server.Transport != "stdio" {
+		// ネットワーク越しに誰でも共有のトークンで操作できる状態にはしない
+		switch {
+		case cfg.Server.AuthKeys != "":
+			keys, err := auth.LoadKeys(cfg.Server.AuthKeys)
+			if err != nil {
+				log.Fatalf("Failed to load auth keys: %v", err)
+			}
+			opts.Keys = keys
+		case !cfg.Server.NoAuth:
+			log.Fatalf("The %s transport requires --auth-keys (or --no-auth for local development)", cfg.Server.Transport)
+		}
+	}
+
+	switch cfg.Server.Transport {
+	case "stdio":
+		err = transport.ServeStdio(ctx, s, subscriptions, os.Stdin, os.Stdout)
+	case "sse":
+		err = transport.ServeSSE(ctx, s, subscriptions, opts)
+	case "http":
+		err = transport.ServeHTTP(ctx, s, subscriptions, opts)
+	}
+	if err != nil && err != context.Canceled {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// verifyCredentials は各チームのAPIトークンで1回だけ検索し、トークンが使えることを確認します
+// チームで共有するトークンがないチームはユーザーごとのトークンを使うため確認しません
+func verifyCredentials(ctx context.Context, teams *docbase.Teams) error {
+	for _, team := range teams.List() {
+		if team.APIToken == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+		_, err := docbase.NewDocBaseClient(team.Domain, team.APIToken).SearchPosts(ctx, docbase.SearchQuery{PerPage: 1})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("team %q: %w", team.Name, err)
+		}
+	}
+	return nil
+}
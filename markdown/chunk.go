@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 分割する位置の優先度です。値が大きいほど区切りとして自然です
+const (
+	lineBoundary = iota + 1
+	paragraphBoundary
+	headingBoundary
+)
+
+type boundary struct {
+	offset int
+	kind   int
+}
+
+// Chunks は text を maxChars 文字以下の部分に分け、各部分の開始位置 (バイト) を返します
+// 見出しの前、段落の間 (空行の後)、行の間の順に、部分の後半にある区切りで分けます
+// 1行が maxChars 文字より長い場合だけ、行の途中で分けます
+func Chunks(text string, maxChars int) []int {
+	starts := []int{0}
+	if maxChars <= 0 {
+		return starts
+	}
+	boundaries := boundaries(text)
+
+	start := 0
+	for {
+		limit := advance(text, start, maxChars)
+		if limit >= len(text) {
+			return starts
+		}
+
+		// start より後で limit 以下の区切りのうち、部分の後半にある最も自然なもの
+		half := advance(text, start, maxChars/2)
+		i := sort.Search(len(boundaries), func(i int) bool { return boundaries[i].offset > start })
+		j := sort.Search(len(boundaries), func(i int) bool { return boundaries[i].offset > limit })
+		next, best := limit, 0
+		for _, b := range boundaries[i:j] {
+			score := b.kind
+			if b.offset >= half {
+				score += headingBoundary
+			}
+			if score >= best {
+				next, best = b.offset, score
+			}
+		}
+
+		starts = append(starts, next)
+		start = next
+	}
+}
+
+// boundaries は text の行の先頭を、区切りとしての優先度つきで返します
+func boundaries(text string) []boundary {
+	var result []boundary
+	var fence string
+	offset := 0
+	prevBlank := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if offset > 0 && offset < len(text) {
+			kind := lineBoundary
+			if fence == "" {
+				content := strings.TrimRight(line, "\r\n")
+				switch {
+				case headingPattern.MatchString(content):
+					kind = headingBoundary
+				case prevBlank && strings.TrimSpace(content) != "":
+					kind = paragraphBoundary
+				}
+			}
+			result = append(result, boundary{offset: offset, kind: kind})
+		}
+
+		content := strings.TrimRight(line, "\r\n")
+		if m := fencePattern.FindStringSubmatch(content); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(content[len(m[0]):]) == "":
+				fence = ""
+			}
+		}
+		prevBlank = strings.TrimSpace(content) == ""
+		offset += len(line)
+	}
+	return result
+}
+
+// advance は text の start から n 文字進んだ位置 (バイト) を返します
+func advance(text string, start, n int) int {
+	pos := start
+	for i := 0; i < n && pos < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunks(t *testing.T) {
+	paragraph := strings.Repeat("あいうえお", 8) + "\n" // 41文字
+	text := "# 設計\n" + paragraph + "\n" + paragraph + "\n## 移行\n" + paragraph + "```\n\n" + paragraph + "```\n"
+
+	starts := Chunks(text, 100)
+	var chunks []string
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		chunks = append(chunks, text[start:end])
+	}
+
+	if strings.Join(chunks, "") != text {
+		t.Fatal("Expected the chunks to cover the text")
+	}
+	for i, c := range chunks {
+		if n := utf8.RuneCountInString(c); n > 100 {
+			t.Errorf("Chunk %d has %d characters", i, n)
+		}
+	}
+	// 段落の間より見出しの前で分ける
+	if len(chunks) != 2 || !strings.HasPrefix(chunks[1], "## 移行\n") {
+		t.Errorf("Unexpected chunks: %q", chunks)
+	}
+
+	// コードブロックの中の空行や # は区切りにしない
+	for _, b := range boundaries("```\n\n# a\n```\n\nb\n") {
+		if b.offset < 11 && b.kind != lineBoundary {
+			t.Errorf("Expected only line boundaries in a code block, but got %+v", b)
+		}
+	}
+
+	// 区切りのない長い行は文字の途中で分けない
+	starts = Chunks(strings.Repeat("あ", 250), 100)
+	if len(starts) != 3 || starts[1] != 300 {
+		t.Errorf("Unexpected starts: %v", starts)
+	}
+}
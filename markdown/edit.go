@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operation はセクションに対する編集の種類です
+type Operation string
+
+const (
+	// Replace は見出しを残して、下位のセクションを含む内容を置き換えます
+	Replace Operation = "replace"
+	// Append は最初の下位の見出しの前 (下位の見出しがなければセクションの終わり) に追加します
+	Append Operation = "append"
+	// Prepend は見出しのすぐ後に追加します
+	Prepend Operation = "prepend"
+	// Delete は見出しと下位のセクションを含むセクション全体を削除します
+	Delete Operation = "delete"
+)
+
+// Edit は path のセクションを op で編集した text を返します
+// セクションの外の部分はバイト単位でそのまま残します
+func Edit(text string, path []string, op Operation, content string) (string, error) {
+	s, err := Find(text, path)
+	if err != nil {
+		return "", err
+	}
+
+	content = withNewline(content)
+	switch op {
+	case Replace:
+		// セクションの後の空行は、次の見出しとの区切りとして残す
+		end := trimBlankLines(text, s.ContentStart, s.End)
+		if s.ContentStart > 0 && text[s.ContentStart-1] != '\n' {
+			content = "\n" + content
+		}
+		return splice(text, s.ContentStart, end, content), nil
+	case Append:
+		at := trimBlankLines(text, s.ContentStart, s.OwnEnd)
+		if at > 0 && text[at-1] != '\n' {
+			content = "\n" + content
+		}
+		return splice(text, at, at, content), nil
+	case Prepend:
+		at := s.ContentStart
+		if at > 0 && text[at-1] != '\n' {
+			content = "\n" + content
+		}
+		return splice(text, at, at, content), nil
+	case Delete:
+		return text[:s.Start] + text[s.End:], nil
+	}
+	return "", fmt.Errorf("unknown operation %q", op)
+}
+
+// trimBlankLines は text の [start, end) の末尾の空行を除いた終わりの位置を返します
+func trimBlankLines(text string, start, end int) int {
+	for end > start {
+		i := strings.LastIndex(text[start:end-1], "\n")
+		lineStart := start
+		if i >= 0 {
+			lineStart = start + i + 1
+		}
+		if strings.TrimSpace(text[lineStart:end]) != "" {
+			break
+		}
+		end = lineStart
+	}
+	return end
+}
+
+func splice(text string, start, end int, content string) string {
+	return text[:start] + content + text[end:]
+}
+
+// withNewline は空でない content の末尾を改行にします
+func withNewline(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content
+}
@@ -0,0 +1,191 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// headingPattern は ATX 形式の見出し (# 見出し) です。末尾の閉じの # は見出しに含めません
+var headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// fencePattern はコードブロックの開始と終了の行です
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// Section は見出しから、同じかより上のレベルの次の見出しの前までの範囲です
+// オフセットはすべて本文のバイト位置です
+type Section struct {
+	Level int
+	Title string
+	// Line は見出しの1から始まる行番号です
+	Line int
+	// Start は見出しの行の先頭、ContentStart は見出しの次の行の先頭です
+	Start, ContentStart int
+	// OwnEnd は最初の下位の見出しの前まで、End は下位の見出しを含むセクションの終わりです
+	OwnEnd, End int
+}
+
+// Sections は text の見出しを出現順に返します。コードブロックの中の # は見出しとして扱いません
+func Sections(text string) []Section {
+	var sections []Section
+	var fence string
+	offset := 0
+	for n, line := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += len(line)
+		content := strings.TrimRight(line, "\r\n")
+
+		if m := fencePattern.FindStringSubmatch(content); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(content[len(m[0]):]) == "":
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		m := headingPattern.FindStringSubmatch(content)
+		if m == nil {
+			continue
+		}
+		sections = append(sections, Section{
+			Level:        len(m[1]),
+			Title:        strings.TrimSpace(m[2]),
+			Line:         n + 1,
+			Start:        start,
+			ContentStart: offset,
+		})
+	}
+
+	// 各セクションの終わりは、後ろの見出しのレベルで決まる
+	for i := range sections {
+		s := &sections[i]
+		s.OwnEnd, s.End = len(text), len(text)
+		if i+1 < len(sections) {
+			s.OwnEnd = sections[i+1].Start
+		}
+		for _, next := range sections[i+1:] {
+			if next.Level <= s.Level {
+				s.End = next.Start
+				break
+			}
+		}
+	}
+	return sections
+}
+
+// ParsePath は "計画 > ロールバック" の形式の見出しのパスを見出しの一覧にします
+// 各見出しの先頭の # は省略できます
+func ParsePath(path string) []string {
+	var titles []string
+	for _, part := range strings.Split(path, ">") {
+		part = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "#"))
+		if part != "" {
+			titles = append(titles, part)
+		}
+	}
+	return titles
+}
+
+// Find は path の見出しのセクションを探します
+// path の各見出しは、前の見出しのセクションの中にある見出しです。大文字と小文字は区別しません
+func Find(text string, path []string) (Section, error) {
+	if len(path) == 0 {
+		return Section{}, fmt.Errorf("the heading path is empty")
+	}
+	sections := Sections(text)
+
+	// 最初の見出しは本文全体から、以降は見つかったセクションの中から探す
+	candidates := sections
+	var found []Section
+	for depth, title := range path {
+		found = nil
+		for _, s := range candidates {
+			if strings.EqualFold(s.Title, title) {
+				found = append(found, s)
+			}
+		}
+		if len(found) == 0 {
+			return Section{}, &NotFoundError{Path: path[:depth+1], Headings: sections}
+		}
+		if depth < len(path)-1 {
+			if len(found) > 1 {
+				return Section{}, &AmbiguousError{Path: path[:depth+1], Matches: found}
+			}
+			candidates = within(sections, found[0])
+		}
+	}
+	if len(found) > 1 {
+		return Section{}, &AmbiguousError{Path: path, Matches: found}
+	}
+	return found[0], nil
+}
+
+// within は parent のセクションの中にある見出しを返します
+func within(sections []Section, parent Section) []Section {
+	var result []Section
+	for _, s := range sections {
+		if s.Start > parent.Start && s.Start < parent.End {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// NotFoundError は見出しが見つからなかったことを表します
+type NotFoundError struct {
+	Path     []string
+	Headings []Section
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Headings) == 0 {
+		return fmt.Sprintf("section %q was not found: the text has no headings", strings.Join(e.Path, " > "))
+	}
+	headings := make([]string, 0, len(e.Headings))
+	for _, s := range e.Headings {
+		headings = append(headings, strings.Repeat("#", s.Level)+" "+s.Title)
+	}
+	return fmt.Sprintf("section %q was not found. The headings are: %s", strings.Join(e.Path, " > "), strings.Join(headings, ", "))
+}
+
+// AmbiguousError は同じ見出しが複数あり、セクションを1つに決められないことを表します
+type AmbiguousError struct {
+	Path    []string
+	Matches []Section
+}
+
+func (e *AmbiguousError) Error() string {
+	lines := make([]string, 0, len(e.Matches))
+	for _, s := range e.Matches {
+		lines = append(lines, fmt.Sprintf("%d", s.Line))
+	}
+	return fmt.Sprintf("section %q matches %d headings (lines %s); add a parent heading to the path, such as \"Parent > %s\"",
+		strings.Join(e.Path, " > "), len(e.Matches), strings.Join(lines, ", "), e.Path[len(e.Path)-1])
+}
+
+// PathAt は text の offset の位置を含むセクションの見出しのパスを返します
+// 最初の見出しより前の位置では空のパスを返します
+func PathAt(text string, offset int) []string {
+	var path []string
+	var levels []int
+	for _, s := range Sections(text) {
+		if s.Start > offset {
+			break
+		}
+		for len(levels) > 0 && levels[len(levels)-1] >= s.Level {
+			path, levels = path[:len(path)-1], levels[:len(levels)-1]
+		}
+		path, levels = append(path, s.Title), append(levels, s.Level)
+	}
+	return path
+}
+
+// LineAt は text の offset の位置の1から始まる行番号を返します
+func LineAt(text string, offset int) int {
+	return strings.Count(text[:min(offset, len(text))], "\n") + 1
+}
@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+const minutes = `# 定例会議
+
+## 議題
+- リリース計画
+
+## Action Items
+- [ ] 田中: 見積もり
+
+### 保留
+- [ ] 鈴木: 調査
+
+## 手順
+` + "```sh\n# コメントは見出しではない\nmake deploy\n```" + `
+
+### Rollback
+1. revert
+2. deploy
+`
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantLine int
+		wantErr  string
+	}{
+		{"Action Items", 6, ""},
+		{"## action items", 6, ""},
+		{"定例会議 > 手順 > Rollback", 18, ""},
+		{"手順 > 保留", 0, `section "手順 > 保留" was not found`},
+		{"コメントは見出しではない", 0, "was not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			s, err := Find(minutes, ParsePath(tt.path))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, but got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if s.Line != tt.wantLine {
+				t.Errorf("Expected the heading at line %d, but got %d", tt.wantLine, s.Line)
+			}
+		})
+	}
+
+	_, err := Find("## 概要\na\n## 概要\nb\n", []string{"概要"})
+	if err == nil || !strings.Contains(err.Error(), "matches 2 headings (lines 1, 3)") {
+		t.Errorf("Expected an ambiguous error, but got %v", err)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		op      Operation
+		content string
+		old     string
+		new     string
+	}{
+		{
+			name:    "append before subsections",
+			path:    "Action Items",
+			op:      Append,
+			content: "- [ ] 佐藤: レビュー",
+			old:     "- [ ] 田中: 見積もり\n\n### 保留",
+			new:     "- [ ] 田中: 見積もり\n- [ ] 佐藤: レビュー\n\n### 保留",
+		},
+		{
+			name:    "prepend",
+			path:    "議題",
+			op:      Prepend,
+			content: "- 前回の振り返り\n",
+			old:     "## 議題\n- リリース計画",
+			new:     "## 議題\n- 前回の振り返り\n- リリース計画",
+		},
+		{
+			name:    "replace keeps the blank lines before the next heading",
+			path:    "Action Items",
+			op:      Replace,
+			content: "- [x] 完了\n",
+			old:     "## Action Items\n- [ ] 田中: 見積もり\n\n### 保留\n- [ ] 鈴木: 調査\n\n## 手順",
+			new:     "## Action Items\n- [x] 完了\n\n## 手順",
+		},
+		{
+			name: "delete",
+			path: "手順 > Rollback",
+			op:   Delete,
+			old:  "```\n\n### Rollback\n1. revert\n2. deploy\n",
+			new:  "```\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Edit(minutes, ParsePath(tt.path), tt.op, tt.content)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if want := strings.Replace(minutes, tt.old, tt.new, 1); got != want {
+				t.Errorf("Unexpected result:\n%s", got)
+			}
+		})
+	}
+
+	// 末尾に改行のない本文でも、行が繋がらない
+	got, err := Edit("## Action Items\n- a", []string{"Action Items"}, Append, "- b")
+	if err != nil || got != "## Action Items\n- a\n- b\n" {
+		t.Errorf("Unexpected result %q, %v", got, err)
+	}
+}
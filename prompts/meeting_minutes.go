@@ -0,0 +1,120 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultMinutesTemplate はチームのテンプレートが指定されていない場合に使う議事録のテンプレートです
+const defaultMinutesTemplate = `# {date} {title}
+
+## 参加者
+
+## アジェンダ
+
+## 議論内容
+
+## 決定事項
+
+## Action Items
+
+- [ ] 担当者: 内容 (期限)
+`
+
+// NewMeetingMinutesPrompt は議事録のプロンプトを作成します
+// templatePostID は template_post_id 引数を省略したときに使うテンプレートの投稿IDで、0 の場合は組み込みのテンプレートを使います
+func NewMeetingMinutesPrompt(templatePostID int64) ServerPrompt {
+	return ServerPrompt{
+		Prompt: newMeetingMinutesPrompt(),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return handleMeetingMinutesRequest(ctx, request, templatePostID)
+		},
+	}
+}
+
+func newMeetingMinutesPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"draft_meeting_minutes",
+		mcp.WithPromptDescription("Draft meeting minutes from raw notes using the team's template post"),
+		mcp.WithArgument(
+			"notes",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Raw notes or a transcript of the meeting"),
+		),
+		mcp.WithArgument(
+			"title",
+			mcp.ArgumentDescription("The title of the meeting"),
+		),
+		mcp.WithArgument(
+			"date",
+			mcp.ArgumentDescription("The date of the meeting in YYYY-MM-DD format (default is today)"),
+		),
+		mcp.WithArgument(
+			"template_post_id",
+			mcp.ArgumentDescription("The ID of the post used as the minutes template (default is the configured minutes template)"),
+		),
+		withTeam(),
+	)
+}
+
+func handleMeetingMinutesRequest(ctx context.Context, request mcp.GetPromptRequest, defaultTemplateID int64) (*mcp.GetPromptResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := request.Params.Arguments["notes"]
+	if notes == "" {
+		return nil, errors.New("notes is required")
+	}
+
+	date := time.Now().Format(dateLayout)
+	if dateStr := request.Params.Arguments["date"]; dateStr != "" {
+		if _, err := time.Parse(dateLayout, dateStr); err != nil {
+			return nil, errors.New("date must be in YYYY-MM-DD format")
+		}
+		date = dateStr
+	}
+
+	// テンプレートの投稿IDは引数、設定の順に探す
+	templateID := defaultTemplateID
+	if templateIDStr := request.Params.Arguments["template_post_id"]; templateIDStr != "" {
+		templateID, err = strconv.ParseInt(templateIDStr, 10, 64)
+		if err != nil {
+			return nil, errors.New("template_post_id must be a valid number")
+		}
+	}
+
+	template := defaultMinutesTemplate
+	if templateID != 0 {
+		post, err := client.GetPost(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+		template = post.Body
+	}
+
+	title := request.Params.Arguments["title"]
+	if title == "" {
+		title = "(infer a short title from the notes)"
+	}
+
+	return mcp.NewGetPromptResult(
+		"Draft meeting minutes",
+		[]mcp.PromptMessage{
+			userMessage(fmt.Sprintf("Here is our team's meeting minutes template:\n\n%s", template)),
+			userMessage(fmt.Sprintf("Here are the raw notes of the meeting held on %s:\n\n%s", date, notes)),
+			userMessage(fmt.Sprintf("Draft the meeting minutes in markdown by filling in the template above with the notes. "+
+				"Keep the headings and order of the template, and leave a section empty rather than inventing content. "+
+				"Use %q as the title and %s as the date. "+
+				"List every decision and action item explicitly, with owners and due dates where the notes mention them. "+
+				"Show me the draft and do not create a post until I confirm it.",
+				title, date)),
+		},
+	), nil
+}
@@ -0,0 +1,89 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ServerPrompt はプロンプトとそのハンドラの組を表します
+type ServerPrompt struct {
+	Prompt  mcp.Prompt
+	Handler server.PromptHandlerFunc
+}
+
+const dateLayout = "2006-01-02"
+
+// withTeam はすべてのプロンプトに共通の team 引数を定義します
+func withTeam() mcp.PromptOption {
+	return mcp.WithArgument(
+		"team",
+		mcp.ArgumentDescription("The name of the DocBase team to use (default is the configured default team)"),
+	)
+}
+
+// newClient は team 引数で指定されたチームのクライアントを作成します
+func newClient(ctx context.Context, request mcp.GetPromptRequest) (*docbase.DocBaseClient, error) {
+	return docbase.NewClient(ctx, request.Params.Arguments["team"])
+}
+
+func parsePostID(request mcp.GetPromptRequest) (int64, error) {
+	postIDStr := request.Params.Arguments["post_id"]
+	if postIDStr == "" {
+		return 0, errors.New("post_id is required")
+	}
+
+	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	if err != nil {
+		return 0, errors.New("post_id must be a valid number")
+	}
+
+	return postID, nil
+}
+
+// formatPost は投稿をプロンプトに埋め込むためのテキストに整形します
+func formatPost(post *docbase.GetPostResponse, withComments bool) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", post.Title)
+	fmt.Fprintf(&b, "- ID: %d\n", post.PostID)
+	if post.URL != "" {
+		fmt.Fprintf(&b, "- URL: %s\n", post.URL)
+	}
+	fmt.Fprintf(&b, "- Author: %s\n", post.User.UserName)
+	if len(post.Tags) > 0 {
+		tags := make([]string, 0, len(post.Tags))
+		for _, tag := range post.Tags {
+			tags = append(tags, tag.Name)
+		}
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(tags, ", "))
+	}
+	fmt.Fprintf(&b, "- Created at: %s\n", post.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Updated at: %s\n", post.UpdatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "\n%s\n", post.Body)
+
+	if withComments {
+		if len(post.Comments) == 0 {
+			b.WriteString("\n## Comments\n\nNo comments.\n")
+		} else {
+			b.WriteString("\n## Comments\n")
+			for _, comment := range post.Comments {
+				fmt.Fprintf(&b, "\n### %s (%s)\n\n%s\n", comment.User.UserName, comment.CreatedAt.Format(time.RFC3339), comment.Body)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func userMessage(text string) mcp.PromptMessage {
+	return mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))
+}
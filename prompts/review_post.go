@@ -0,0 +1,59 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func NewReviewPostPrompt() ServerPrompt {
+	return ServerPrompt{
+		Prompt:  newReviewPostPrompt(),
+		Handler: handleReviewPostRequest,
+	}
+}
+
+func newReviewPostPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"review_outdated_post",
+		mcp.WithPromptDescription("Review a DocBase post for outdated information"),
+		mcp.WithArgument(
+			"post_id",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID of the post to review"),
+		),
+		withTeam(),
+	)
+}
+
+func handleReviewPostRequest(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := parsePostID(request)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := client.GetPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Review of post %d for outdated information", postID),
+		[]mcp.PromptMessage{
+			userMessage(formatPost(post, true)),
+			userMessage(fmt.Sprintf("Today is %s and the post above was last updated on %s. "+
+				"Review it for information that is likely to be outdated: versions, dates, deadlines, people and teams, links, procedures and statements written as current. "+
+				"Also take into account corrections pointed out in the comments. "+
+				"For each finding, quote the relevant part, explain why it may be outdated and suggest how to update it. "+
+				"Do not update the post yourself.",
+				time.Now().Format(dateLayout), post.UpdatedAt.Format(dateLayout))),
+		},
+	), nil
+}
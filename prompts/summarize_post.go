@@ -0,0 +1,55 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func NewSummarizePostPrompt() ServerPrompt {
+	return ServerPrompt{
+		Prompt:  newSummarizePostPrompt(),
+		Handler: handleSummarizePostRequest,
+	}
+}
+
+func newSummarizePostPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"summarize_post",
+		mcp.WithPromptDescription("Summarize a DocBase post together with its comments"),
+		mcp.WithArgument(
+			"post_id",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID of the post to summarize"),
+		),
+		withTeam(),
+	)
+}
+
+func handleSummarizePostRequest(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := parsePostID(request)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := client.GetPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Summary of post %d", postID),
+		[]mcp.PromptMessage{
+			userMessage(formatPost(post, true)),
+			userMessage("Summarize the DocBase post above. " +
+				"Start with a few sentences on its purpose and conclusion, then list the key points. " +
+				"Finally, summarize the discussion in the comments, including any decisions made and open questions that remain."),
+		},
+	), nil
+}
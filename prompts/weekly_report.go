@@ -0,0 +1,113 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// weeklyReportMaxPosts は週報のために取得する投稿の上限です
+const weeklyReportMaxPosts = 100
+
+func NewWeeklyReportPrompt() ServerPrompt {
+	return ServerPrompt{
+		Prompt:  newWeeklyReportPrompt(),
+		Handler: handleWeeklyReportRequest,
+	}
+}
+
+func newWeeklyReportPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"write_weekly_report",
+		mcp.WithPromptDescription("Write a weekly report from the posts a user wrote in a date range"),
+		mcp.WithArgument(
+			"author",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The DocBase user name whose posts the report is based on"),
+		),
+		mcp.WithArgument(
+			"from",
+			mcp.ArgumentDescription("The first day of the range in YYYY-MM-DD format (default is Monday of this week)"),
+		),
+		mcp.WithArgument(
+			"to",
+			mcp.ArgumentDescription("The last day of the range in YYYY-MM-DD format (default is today)"),
+		),
+		withTeam(),
+	)
+}
+
+func handleWeeklyReportRequest(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	author := request.Params.Arguments["author"]
+	if author == "" {
+		return nil, errors.New("author is required")
+	}
+
+	// デフォルトは今週の月曜日から今日まで
+	now := time.Now()
+	to := now
+	from := now.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+
+	if fromStr := request.Params.Arguments["from"]; fromStr != "" {
+		t, err := time.Parse(dateLayout, fromStr)
+		if err != nil {
+			return nil, errors.New("from must be in YYYY-MM-DD format")
+		}
+		from = t
+	}
+
+	if toStr := request.Params.Arguments["to"]; toStr != "" {
+		t, err := time.Parse(dateLayout, toStr)
+		if err != nil {
+			return nil, errors.New("to must be in YYYY-MM-DD format")
+		}
+		to = t
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	fromStr, toStr := from.Format(dateLayout), to.Format(dateLayout)
+
+	result, err := client.SearchPosts(ctx, docbase.SearchQuery{
+		Q:       fmt.Sprintf("author:%s created_at:%s~%s", author, fromStr, toStr),
+		Page:    1,
+		PerPage: weeklyReportMaxPosts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Posts written by %s from %s to %s (%d found):\n", author, fromStr, toStr, result.Meta.Total)
+	for i := range result.Posts {
+		b.WriteString("\n---\n\n")
+		b.WriteString(formatPost(&result.Posts[i], false))
+	}
+	if result.Meta.Total > len(result.Posts) {
+		fmt.Fprintf(&b, "\n---\n\nOnly the first %d posts are included.\n", len(result.Posts))
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Weekly report for %s from %s to %s", author, fromStr, toStr),
+		[]mcp.PromptMessage{
+			userMessage(b.String()),
+			userMessage("Write a weekly report in markdown based on the posts above. " +
+				"Group the work into themes, and for each theme summarize what was done with links to the relevant posts. " +
+				"Add sections for problems encountered and plans for next week when the posts mention them. " +
+				"If no posts were found, say so instead of inventing work."),
+		},
+	), nil
+}
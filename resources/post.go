@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const postURIPrefix = "docbase://posts/"
+
+// PostURI は投稿IDに対応するリソースURIを返します
+// team が空の場合は既定のチームの投稿を表します
+func PostURI(team string, postID int64) string {
+	uri := fmt.Sprintf("%s%d", postURIPrefix, postID)
+	if team != "" {
+		uri += "?team=" + url.QueryEscape(team)
+	}
+	return uri
+}
+
+// ParsePostURI は docbase://posts/{id}?team={team} 形式のURIからチーム名と投稿IDを取り出します
+func ParsePostURI(uri string) (string, int64, error) {
+	rest, ok := strings.CutPrefix(uri, postURIPrefix)
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported resource URI: %s", uri)
+	}
+
+	idStr, rawQuery, _ := strings.Cut(rest, "?")
+	postID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || postID <= 0 {
+		return "", 0, fmt.Errorf("invalid post ID in resource URI: %s", uri)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid query in resource URI: %s", uri)
+	}
+
+	return query.Get("team"), postID, nil
+}
+
+func NewPostTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		postURIPrefix+"{id}{?team}",
+		"DocBase post",
+		mcp.WithTemplateDescription("A DocBase post as markdown. The team query selects a team other than the default one. Subscribe to get notified when it is updated."),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	)
+}
+
+func HandleReadPost(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	team, postID, err := ParsePostURI(request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := docbase.NewClient(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := client.GetPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/markdown",
+			Text:     fmt.Sprintf("# %s\n\n%s", post.Title, post.Body),
+		},
+	}, nil
+}
@@ -0,0 +1,294 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultPollInterval は購読中の投稿を確認する既定の間隔です
+	DefaultPollInterval = time.Minute
+	// DefaultRateLimitReserve はポーリングで使い切らずに残しておく既定のリクエスト数です
+	DefaultRateLimitReserve = 50
+)
+
+type messageHandler interface {
+	HandleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage
+}
+
+// watchKey は購読を投稿とチーム、APIトークンの組で区別します
+// ユーザーごとに別のトークンを使う場合に、他人のトークンで見える投稿の更新が漏れないようにするためです
+type watchKey struct {
+	uri    string
+	domain string
+	token  string
+}
+
+// watch は1つの投稿に対する購読状態を表します
+type watch struct {
+	postID    int64
+	client    *docbase.DocBaseClient
+	updatedAt time.Time
+	sessions  map[string]server.ClientSession
+}
+
+// Subscriptions は resources/subscribe と resources/unsubscribe を処理し、
+// 購読中の投稿を updated_at で定期的に監視して notifications/resources/updated を送ります
+// mcp-go はこれらのメソッドを扱わないため、それ以外のメッセージは next に委譲します
+type Subscriptions struct {
+	next messageHandler
+
+	// NewClient は購読したクライアントのコンテキストからチームのDocBaseクライアントを作成します
+	NewClient func(ctx context.Context, team string) (*docbase.DocBaseClient, error)
+	// Interval は投稿を確認する間隔です
+	Interval time.Duration
+	// Reserve はレート制限の残りリクエスト数がこの値以下になるとポーリングを止めます
+	// ツール呼び出しのために予算を残しておくためのものです
+	Reserve int
+
+	mu      sync.Mutex
+	watches map[watchKey]*watch
+	// clients はレート制限の状態を共有するため、チームとAPIトークンごとにクライアントを使い回します
+	// 購読がなくなったチームとトークンのクライアントは破棄します
+	clients map[string]*docbase.DocBaseClient
+}
+
+func NewSubscriptions(next messageHandler) *Subscriptions {
+	return &Subscriptions{
+		next:      next,
+		NewClient: docbase.NewClient,
+		Interval:  DefaultPollInterval,
+		Reserve:   DefaultRateLimitReserve,
+		watches:   make(map[watchKey]*watch),
+		clients:   make(map[string]*docbase.DocBaseClient),
+	}
+}
+
+// HandleMessage は購読関連のリクエストを処理し、それ以外を next に渡します
+func (s *Subscriptions) HandleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	var base struct {
+		Method string        `json:"method"`
+		ID     mcp.RequestId `json:"id,omitempty"`
+	}
+	if err := json.Unmarshal(message, &base); err != nil || base.ID == nil {
+		return s.next.HandleMessage(ctx, message)
+	}
+
+	switch base.Method {
+	case "resources/subscribe":
+		var request mcp.SubscribeRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			return mcp.NewJSONRPCError(base.ID, mcp.INVALID_REQUEST, "Invalid subscribe request", nil)
+		}
+		if err := s.subscribe(ctx, request.Params.URI); err != nil {
+			return mcp.NewJSONRPCError(base.ID, mcp.INVALID_PARAMS, err.Error(), nil)
+		}
+		return mcp.NewJSONRPCResponse(base.ID, mcp.Result{})
+	case "resources/unsubscribe":
+		var request mcp.UnsubscribeRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			return mcp.NewJSONRPCError(base.ID, mcp.INVALID_REQUEST, "Invalid unsubscribe request", nil)
+		}
+		s.unsubscribe(ctx, request.Params.URI)
+		return mcp.NewJSONRPCResponse(base.ID, mcp.Result{})
+	default:
+		return s.next.HandleMessage(ctx, message)
+	}
+}
+
+func (s *Subscriptions) subscribe(ctx context.Context, uri string) error {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return errors.New("subscriptions require a client session")
+	}
+
+	team, postID, err := ParsePostURI(uri)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.client(ctx, team)
+	if err != nil {
+		return err
+	}
+	key := watchKey{uri: uri, domain: client.Domain, token: client.APIToken}
+
+	// 基準となる updated_at を取得する。存在しない投稿や見えない投稿の購読はここで弾かれる
+	post, err := client.GetPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.watches[key]
+	if !ok {
+		// 同じチームとトークンの購読が並行して作られた場合は、先に登録されたクライアントを使う
+		if cached, ok := s.clients[clientKey(client)]; ok {
+			client = cached
+		} else {
+			s.clients[clientKey(client)] = client
+		}
+		w = &watch{
+			postID:    postID,
+			client:    client,
+			updatedAt: post.UpdatedAt,
+			sessions:  make(map[string]server.ClientSession),
+		}
+		s.watches[key] = w
+	}
+	w.sessions[session.SessionID()] = session
+
+	return nil
+}
+
+func (s *Subscriptions) unsubscribe(ctx context.Context, uri string) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, w := range s.watches {
+		if key.uri == uri {
+			s.unwatch(key, w, session.SessionID())
+		}
+	}
+}
+
+// unwatch は key の購読から sessionID のセッションを外します
+// 購読するセッションがなくなれば監視をやめ、そのクライアントを使う購読がなくなればクライアントも破棄します
+// s.mu を取得してから呼び出します
+func (s *Subscriptions) unwatch(key watchKey, w *watch, sessionID string) {
+	delete(w.sessions, sessionID)
+	if len(w.sessions) > 0 {
+		return
+	}
+	delete(s.watches, key)
+	for other := range s.watches {
+		if other.domain == key.domain && other.token == key.token {
+			return
+		}
+	}
+	delete(s.clients, key.domain+"\x00"+key.token)
+}
+
+// client は ctx のAPIトークンとチームに対応するクライアントを返します
+func (s *Subscriptions) client(ctx context.Context, team string) (*docbase.DocBaseClient, error) {
+	client, err := s.NewClient(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	// 購読が作られるまではキャッシュに登録しない。登録は subscribe が行う
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.clients[clientKey(client)]; ok {
+		return cached, nil
+	}
+	return client, nil
+}
+
+func clientKey(client *docbase.DocBaseClient) string {
+	return client.Domain + "\x00" + client.APIToken
+}
+
+// RemoveSession は切断されたセッションの購読をすべて解除します
+func (s *Subscriptions) RemoveSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, w := range s.watches {
+		s.unwatch(key, w, sessionID)
+	}
+}
+
+// Run は ctx がキャンセルされるまで Interval ごとに購読中の投稿を確認します
+func (s *Subscriptions) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+		}
+	}
+}
+
+func (s *Subscriptions) poll(ctx context.Context) {
+	s.mu.Lock()
+	targets := make(map[watchKey]*watch, len(s.watches))
+	for key, w := range s.watches {
+		targets[key] = w
+	}
+	s.mu.Unlock()
+
+	for key, target := range targets {
+		if !s.hasBudget(target.client) {
+			// レート制限はトークンごとなので、他のトークンの購読は続けて確認する
+			continue
+		}
+
+		post, err := target.client.GetPost(ctx, target.postID)
+		if err != nil {
+			log.Printf("Failed to poll %s: %v", key.uri, err)
+			continue
+		}
+
+		s.mu.Lock()
+		w, ok := s.watches[key]
+		if !ok || !post.UpdatedAt.After(w.updatedAt) {
+			s.mu.Unlock()
+			continue
+		}
+		w.updatedAt = post.UpdatedAt
+		sessions := make([]server.ClientSession, 0, len(w.sessions))
+		for _, session := range w.sessions {
+			sessions = append(sessions, session)
+		}
+		s.mu.Unlock()
+
+		notifyUpdated(sessions, key.uri)
+	}
+}
+
+// hasBudget は client でポーリングに使えるリクエストが残っているかを返します
+func (s *Subscriptions) hasBudget(client *docbase.DocBaseClient) bool {
+	rl := client.RateLimit()
+	if rl.Limit == 0 || time.Now().After(rl.Reset) {
+		return true
+	}
+	return rl.Remaining > s.Reserve
+}
+
+func notifyUpdated(sessions []server.ClientSession, uri string) {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/resources/updated",
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{"uri": uri},
+			},
+		},
+	}
+
+	for _, session := range sessions {
+		select {
+		case session.NotificationChannel() <- notification:
+		default:
+			log.Printf("Dropped update notification for %s: session %s is not reading", uri, session.SessionID())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) SessionID() string { return "test" }
+
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+
+func TestSubscriptionsNotifyOnUpdate(t *testing.T) {
+	var updatedAt atomic.Value
+	updatedAt.Store("2025-01-01T00:00:00+09:00")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": 1, "title": "test", "updated_at": %q}`, updatedAt.Load())
+	}))
+	defer ts.Close()
+
+	client := docbase.NewDocBaseClient("example", "test-token")
+	client.BaseURL = ts.URL
+
+	s := server.NewMCPServer("test", "0.0.1", server.WithResourceCapabilities(true, false))
+	subscriptions := NewSubscriptions(s)
+	subscriptions.NewClient = func(context.Context, string) (*docbase.DocBaseClient, error) { return client, nil }
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 1)}
+	ctx := s.WithContext(context.Background(), session)
+
+	response := subscriptions.HandleMessage(ctx, json.RawMessage(
+		`{"jsonrpc": "2.0", "id": 1, "method": "resources/subscribe", "params": {"uri": "docbase://posts/1"}}`,
+	))
+	if _, ok := response.(mcp.JSONRPCResponse); !ok {
+		t.Fatalf("Expected a successful response, but got %#v", response)
+	}
+
+	subscriptions.poll(ctx)
+	if len(session.notifications) != 0 {
+		t.Fatal("Expected no notification while the post is unchanged")
+	}
+
+	updatedAt.Store("2025-01-02T00:00:00+09:00")
+	subscriptions.poll(ctx)
+
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != "notifications/resources/updated" {
+			t.Errorf("Unexpected notification method: %s", notification.Method)
+		}
+		if uri := notification.Params.AdditionalFields["uri"]; uri != "docbase://posts/1" {
+			t.Errorf("Unexpected notification URI: %v", uri)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an update notification")
+	}
+}
+
+func TestSubscriptionsRespectRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "300")
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Minute).Unix()))
+		fmt.Fprint(w, `{"id": 1, "title": "test"}`)
+	}))
+	defer ts.Close()
+
+	client := docbase.NewDocBaseClient("example", "test-token")
+	client.BaseURL = ts.URL
+	subscriptions := NewSubscriptions(nil)
+
+	if !subscriptions.hasBudget(client) {
+		t.Error("Expected budget while the rate limit is unknown")
+	}
+
+	if _, err := client.GetPost(context.Background(), 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if subscriptions.hasBudget(client) {
+		t.Error("Expected no budget when the remaining requests are within the reserve")
+	}
+
+	subscriptions.Reserve = 5
+	if !subscriptions.hasBudget(client) {
+		t.Error("Expected budget when the remaining requests exceed the reserve")
+	}
+}
+
+func TestSubscriptionsDropUnusedClients(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "title": "test", "updated_at": "2025-01-01T00:00:00+09:00"}`)
+	}))
+	defer ts.Close()
+
+	s := server.NewMCPServer("test", "0.0.1", server.WithResourceCapabilities(true, false))
+	subscriptions := NewSubscriptions(s)
+	subscriptions.NewClient = func(context.Context, string) (*docbase.DocBaseClient, error) {
+		client := docbase.NewDocBaseClient("example", "test-token")
+		client.BaseURL = ts.URL
+		return client, nil
+	}
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 1)}
+	ctx := s.WithContext(context.Background(), session)
+	for _, uri := range []string{"docbase://posts/1", "docbase://posts/2"} {
+		if err := subscriptions.subscribe(ctx, uri); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(subscriptions.clients) != 1 {
+		t.Fatalf("Expected one shared client, but got %d", len(subscriptions.clients))
+	}
+
+	// 同じクライアントを使う購読が残っている間は破棄しない
+	subscriptions.unsubscribe(ctx, "docbase://posts/1")
+	if len(subscriptions.clients) != 1 {
+		t.Errorf("Expected the client to be kept while a watch uses it, but got %d clients", len(subscriptions.clients))
+	}
+
+	subscriptions.RemoveSession(session.SessionID())
+	if len(subscriptions.watches) != 0 || len(subscriptions.clients) != 0 {
+		t.Errorf("Expected no watches and clients, but got %d watches and %d clients", len(subscriptions.watches), len(subscriptions.clients))
+	}
+}
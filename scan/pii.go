@@ -0,0 +1,55 @@
+package scan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// piiRules は個人情報の形式です
+var piiRules = []Rule{
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	// 携帯電話 (090-1234-5678)、固定電話 (03-1234-5678)、国際表記 (+81 90 1234 5678)
+	{"phone_number", regexp.MustCompile(`(?:\+81[ -]?\(?0?\)?[1-9]\d{0,3}[ -]?\d{1,4}[ -]?\d{4}|\b0[789]0-?\d{4}-?\d{4}|\b0\d{1,4}-\d{1,4}-\d{4})\b`)},
+}
+
+// myNumberPattern はマイナンバー (個人番号) の候補です
+var myNumberPattern = regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}\b`)
+
+// NewPIIScanner は組み込みのパターンと extra のパターンで個人情報を探す Scanner を作成します
+func NewPIIScanner(extra []Rule) *Scanner {
+	return &Scanner{
+		rules: append(append([]Rule(nil), piiRules...), extra...),
+		extra: []func(string) []Finding{myNumbers},
+	}
+}
+
+// myNumbers は検査用数字が正しい12桁の数字をマイナンバーとして検出します
+func myNumbers(text string) []Finding {
+	var findings []Finding
+	for _, m := range myNumberPattern.FindAllStringIndex(text, -1) {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(text[m[0]:m[1]])
+		if validMyNumber(digits) {
+			findings = append(findings, Finding{Rule: "my_number", start: m[0], end: m[1]})
+		}
+	}
+	return findings
+}
+
+// validMyNumber は12桁の数字の最後の桁が検査用数字として正しいかを返します
+func validMyNumber(digits string) bool {
+	sum := 0
+	// 検査用数字を除いた11桁を、下の桁から n = 1..11 として重み付けする
+	for n := 1; n <= 11; n++ {
+		p := int(digits[11-n] - '0')
+		q := n + 1
+		if n >= 7 {
+			q = n - 5
+		}
+		sum += p * q
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	return int(digits[11]-'0') == check
+}
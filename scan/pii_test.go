@@ -0,0 +1,25 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPIIScanner(t *testing.T) {
+	text := strings.Join([]string{
+		"担当: taro.yamada@example.co.jp",
+		"携帯 090-1234-5678 / 代表 03-1234-5678 / +81 90 1234 5678",
+		"個人番号 1234 5678 9018",
+		"注文番号 1234 5678 9012 と 2024-01-15 は対象外",
+	}, "\n")
+
+	var rules []string
+	for _, f := range NewPIIScanner(nil).Scan("body", text) {
+		rules = append(rules, f.Rule)
+	}
+
+	want := []string{"email", "phone_number", "phone_number", "phone_number", "my_number"}
+	if strings.Join(rules, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, but got %v", want, rules)
+	}
+}
@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action は検出したときの扱いを表します
+type Action string
+
+const (
+	// ActionBlock は送信を中止します
+	ActionBlock Action = "block"
+	// ActionRedact は検出した箇所を伏せ字にして送信します
+	ActionRedact Action = "redact"
+	// ActionWarn はそのまま送信し、検出した箇所を報告します
+	ActionWarn Action = "warn"
+	// ActionAllow は検査しません
+	ActionAllow Action = "allow"
+)
+
+// ParseAction は設定の文字列を Action に変換します
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionBlock, ActionRedact, ActionWarn, ActionAllow:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown action %q (must be block, redact, warn or allow)", s)
+}
+
+// Rule は検出する文字列のパターンです
+// パターンに secret という名前のグループがある場合は、そのグループだけを検出した箇所として扱います
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// NewRule は正規表現をコンパイルして Rule を作成します
+func NewRule(name, pattern string) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid pattern for %q: %w", name, err)
+	}
+	return Rule{Name: name, Pattern: re}, nil
+}
+
+// Finding は検出した箇所を表します
+type Finding struct {
+	Rule   string
+	Field  string // 検出した引数の名前 (body など)
+	Line   int    // 1から始まる行番号
+	Column int    // 1から始まる文字単位の桁
+	// Preview は検出した文字列を伏せた表示用の文字列です。元の値は含めません
+	Preview string
+
+	start, end int
+}
+
+// String は "body line 3, column 7: aws_access_key_id (AKIA…, 20 chars)" の形式で返します
+func (f Finding) String() string {
+	return fmt.Sprintf("%s line %d, column %d: %s (%s)", f.Field, f.Line, f.Column, f.Rule, f.Preview)
+}
+
+// Scanner は rules に一致する箇所を探します
+type Scanner struct {
+	rules []Rule
+	// extra はパターン以外の方法で検出する箇所を返します (エントロピーの高い文字列など)
+	extra []func(text string) []Finding
+}
+
+// Scan は text の中で一致した箇所を位置の順に返します
+// 重なる箇所は先に始まるもの (同じ位置なら長いもの) にまとめ、その範囲を重なったすべての箇所の和に広げます
+func (s *Scanner) Scan(field, text string) []Finding {
+	var findings []Finding
+	for _, rule := range s.rules {
+		group := rule.Pattern.SubexpIndex("secret")
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if group > 0 && m[2*group] >= 0 {
+				start, end = m[2*group], m[2*group+1]
+			}
+			findings = append(findings, Finding{Rule: rule.Name, start: start, end: end})
+		}
+	}
+	for _, detect := range s.extra {
+		findings = append(findings, detect(text)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].start != findings[j].start {
+			return findings[i].start < findings[j].start
+		}
+		return findings[i].end > findings[j].end
+	})
+
+	var result []Finding
+	last := -1
+	for _, f := range findings {
+		if f.start < last {
+			// 重なる箇所は伏せ字にする範囲を広げるだけで、別の箇所としては返さない
+			prev := &result[len(result)-1]
+			last = max(last, f.end)
+			prev.end = last
+			continue
+		}
+		last = f.end
+		f.Field = field
+		f.Line, f.Column = position(text, f.start)
+		result = append(result, f)
+	}
+	for i := range result {
+		result[i].Preview = preview(text[result[i].start:result[i].end])
+	}
+	return result
+}
+
+// ExcludeKnown は findings から、検出した文字列が known にも含まれるものを除いて返します
+// 既に送信先にある内容を、編集のたびに検出し直さないために使います
+func ExcludeKnown(text string, findings []Finding, known string) []Finding {
+	var result []Finding
+	for _, f := range findings {
+		if known != "" && strings.Contains(known, text[f.start:f.end]) {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// Redact は findings の箇所を [REDACTED:rule] に置き換えた text を返します
+// findings は同じ text に対する Scan の結果でなければなりません
+func Redact(text string, findings []Finding) string {
+	var b strings.Builder
+	pos := 0
+	for _, f := range findings {
+		b.WriteString(text[pos:f.start])
+		fmt.Fprintf(&b, "[REDACTED:%s]", f.Rule)
+		pos = f.end
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+func position(text string, offset int) (line, column int) {
+	before := text[:offset]
+	line = strings.Count(before, "\n") + 1
+	column = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
+
+// preview は値を伏せて、先頭の数文字と長さだけを表示します
+func preview(s string) string {
+	n := utf8.RuneCountInString(s)
+	if n <= 8 {
+		return fmt.Sprintf("%d chars", n)
+	}
+	return fmt.Sprintf("%s…, %d chars", string([]rune(s)[:4]), n)
+}
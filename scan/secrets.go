@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// secretRules はよく使われる認証情報の形式です
+var secretRules = []Rule{
+	{"aws_access_key_id", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws_secret_access_key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"stripe_key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----)?`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"connection_string_password", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:(?P<secret>[^\s@/]+)@`)},
+	{"password_assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|api_?key|access_?token)["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;]{8,})`)},
+}
+
+// highEntropyPattern はエントロピーを調べる候補の文字列です
+var highEntropyPattern = regexp.MustCompile(`[A-Za-z0-9+/_\-]{24,}={0,2}`)
+
+// urlPattern は URL です。パスやクエリの ID はランダムに見えてもトークンとして扱わない
+var urlPattern = regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s<>"'()\[\]]+`)
+
+// minEntropy はランダムなトークンとみなす1文字あたりのエントロピー (bit) です
+// 16進数の文字列 (コミットのハッシュなど) は最大でも4.0なので検出しません
+const minEntropy = 4.2
+
+// NewSecretScanner は組み込みのパターンと extra のパターンで認証情報を探す Scanner を作成します
+// entropy が true の場合は、パターンに一致しないランダムな文字列もトークンとして検出します
+func NewSecretScanner(extra []Rule, entropy bool) *Scanner {
+	s := &Scanner{rules: append(append([]Rule(nil), secretRules...), extra...)}
+	if entropy {
+		s.extra = append(s.extra, highEntropyTokens)
+	}
+	return s
+}
+
+func highEntropyTokens(text string) []Finding {
+	var findings []Finding
+	urls := urlPattern.FindAllStringIndex(text, -1)
+	for _, m := range highEntropyPattern.FindAllStringIndex(text, -1) {
+		if inURL(urls, m[0]) {
+			continue
+		}
+		token := text[m[0]:m[1]]
+		// 英字と数字が混ざっていないものは単語や識別子とみなす
+		if !strings.ContainsAny(token, "0123456789") || strings.ToLower(token) == token || strings.ToUpper(token) == token {
+			continue
+		}
+		if shannonEntropy(token) < minEntropy {
+			continue
+		}
+		findings = append(findings, Finding{Rule: "high_entropy_token", start: m[0], end: m[1]})
+	}
+	return findings
+}
+
+// inURL は offset が urls のいずれかの範囲にあるかを返します
+func inURL(urls [][]int, offset int) bool {
+	for _, u := range urls {
+		if u[0] <= offset && offset < u[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultDir はサーバーの状態を保存する既定のディレクトリ ($XDG_STATE_HOME/docbase-mcp-server) を返します
+func DefaultDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "docbase-mcp-server")
+}
+
+// File は1つのJSONファイルに保存する値です
+// 書き込みは一時ファイルに書いてから置き換えるため、途中で終了しても壊れたファイルは残りません
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile は dir の name というファイルに保存する File を作成します
+// dir が空の場合は何も保存しない File を返します
+func NewFile(dir, name string) *File {
+	if dir == "" {
+		return &File{}
+	}
+	return &File{path: filepath.Join(dir, name)}
+}
+
+// Update はファイルの内容を v に読み込んで update を呼び出し、update がエラーを返さなければ v を保存します
+// 同じプロセスの中では Update は1つずつ実行されます。ファイルが存在しない場合 v はゼロ値のままです
+func (f *File) Update(v interface{}, update func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(v); err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	return f.save(v)
+}
+
+// Load はファイルの内容を v に読み込みます。ファイルが存在しない場合 v はゼロ値のままです
+func (f *File) Load(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load(v)
+}
+
+func (f *File) load(v interface{}) error {
+	if f.path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+	return nil
+}
+
+func (f *File) save(v interface{}) error {
+	if f.path == "" {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// 投稿の内容を含むことがあるため、本人だけが読めるようにする
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type counter struct {
+	Names []string `json:"names"`
+	Count int      `json:"count"`
+}
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	f := NewFile(dir, "counter.json")
+
+	// ファイルがなければゼロ値のまま
+	var v counter
+	if err := f.Load(&v); err != nil || v.Count != 0 {
+		t.Fatalf("Expected the zero value, but got %+v, %v", v, err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		var v counter
+		err := f.Update(&v, func() error {
+			v.Names = append(v.Names, name)
+			v.Count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// 別の File からも保存した内容を読める
+	var loaded counter
+	if err := NewFile(dir, "counter.json").Load(&loaded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if loaded.Count != 2 || len(loaded.Names) != 2 || loaded.Names[1] != "b" {
+		t.Errorf("Expected both updates to be saved, but got %+v", loaded)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("Expected the directory to be 0700, but got %o", perm)
+	}
+	// 一時ファイルは残らない
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only counter.json, but got %v", entries)
+	}
+
+	// update がエラーを返した場合は保存しない
+	failure := errors.New("failure")
+	err = f.Update(&loaded, func() error {
+		loaded.Count = 100
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the error of update, but got %v", err)
+	}
+	var after counter
+	if err := f.Load(&after); err != nil || after.Count != 2 {
+		t.Errorf("Expected the file to be unchanged, but got %+v, %v", after, err)
+	}
+}
+
+func TestFileWithoutDir(t *testing.T) {
+	f := NewFile("", "counter.json")
+	v := counter{Count: 1}
+	if err := f.Update(&v, func() error { v.Count++; return nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var loaded counter
+	if err := f.Load(&loaded); err != nil || loaded.Count != 0 {
+		t.Errorf("Expected nothing to be saved, but got %+v, %v", loaded, err)
+	}
+}
+
+func TestFileBroken(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "counter.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var v counter
+	if err := NewFile(dir, "counter.json").Load(&v); err == nil {
+		t.Error("Expected an error for a broken file")
+	}
+}
@@ -0,0 +1,158 @@
+package textdiff
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict は両方の側が同じ箇所を異なる内容に変えた箇所です
+type Conflict struct {
+	// Line は base で衝突した範囲の1から始まる行番号です。挿入どうしの衝突では挿入位置の次の行です
+	Line int
+	Base []string
+	Ours []string
+	// Theirs は相手側の内容です
+	Theirs []string
+}
+
+// MergeResult は3方向のマージの結果です
+type MergeResult struct {
+	// Text はマージした文字列です。衝突がある場合は衝突した箇所にマーカーを含みます
+	Text      string
+	Conflicts []Conflict
+}
+
+// Markers は衝突した箇所を示すマーカーに添える名前です
+type Markers struct {
+	Ours, Base, Theirs string
+}
+
+// hunk は base の [start, end) の行を lines に置き換える変更です
+type hunk struct {
+	start, end int
+	lines      []string
+	theirs     bool
+}
+
+// Merge は base からの ours と theirs の変更を行単位で合わせます
+// 両方が同じ箇所を異なる内容に変えた場合は、その箇所を diff3 形式のマーカーで囲み Conflicts に加えます
+func Merge(base, ours, theirs string, markers Markers) MergeResult {
+	baseLines := SplitLines(base)
+	hunks := append(changes(baseLines, SplitLines(ours), false), changes(baseLines, SplitLines(theirs), true)...)
+	slices.SortStableFunc(hunks, func(a, b hunk) int { return a.start - b.start })
+
+	var result MergeResult
+	var out []string
+	pos := 0
+	for i := 0; i < len(hunks); {
+		// 重なる変更を1つのグループにまとめる
+		start, end := hunks[i].start, hunks[i].end
+		j := i + 1
+		for j < len(hunks) && overlaps(hunks[j], start, end) {
+			end = max(end, hunks[j].end)
+			j++
+		}
+		group := hunks[i:j]
+		i = j
+
+		out = append(out, baseLines[pos:start]...)
+		pos = end
+
+		oursLines := apply(baseLines, start, end, group, false)
+		theirsLines := apply(baseLines, start, end, group, true)
+		switch {
+		case !hasSide(group, true):
+			out = append(out, oursLines...)
+		case !hasSide(group, false) || slices.Equal(oursLines, theirsLines):
+			out = append(out, theirsLines...)
+		default:
+			result.Conflicts = append(result.Conflicts, Conflict{
+				Line:   start + 1,
+				Base:   baseLines[start:end],
+				Ours:   oursLines,
+				Theirs: theirsLines,
+			})
+			out = append(out, "<<<<<<< "+markers.Ours)
+			out = append(out, oursLines...)
+			out = append(out, "||||||| "+markers.Base)
+			out = append(out, baseLines[start:end]...)
+			out = append(out, "=======")
+			out = append(out, theirsLines...)
+			out = append(out, ">>>>>>> "+markers.Theirs)
+		}
+	}
+	out = append(out, baseLines[pos:]...)
+
+	result.Text = joinLines(out, base, ours, theirs)
+	return result
+}
+
+// changes は base を other に変える編集を、連続する変更ごとの hunk にまとめます
+func changes(base, other []string, theirs bool) []hunk {
+	var hunks []hunk
+	var cur *hunk
+	for _, e := range Lines(base, other) {
+		if e.Kind == Equal {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			hunks = append(hunks, hunk{start: e.A, end: e.A, theirs: theirs})
+			cur = &hunks[len(hunks)-1]
+		}
+		if e.Kind == Delete {
+			cur.end = e.A + 1
+		} else {
+			cur.lines = append(cur.lines, e.Line)
+		}
+	}
+	return hunks
+}
+
+// overlaps は h が base の [start, end) の変更と重なるかを返します
+// 同じ位置への挿入も、どちらを先にするか決められないため重なるものとします
+func overlaps(h hunk, start, end int) bool {
+	return h.start < end || h.start == start
+}
+
+func hasSide(group []hunk, theirs bool) bool {
+	for _, h := range group {
+		if h.theirs == theirs {
+			return true
+		}
+	}
+	return false
+}
+
+// apply は base の [start, end) に group のうち一方の側の変更を適用した行を返します
+func apply(base []string, start, end int, group []hunk, theirs bool) []string {
+	var lines []string
+	pos := start
+	for _, h := range group {
+		if h.theirs != theirs {
+			continue
+		}
+		lines = append(lines, base[pos:h.start]...)
+		lines = append(lines, h.lines...)
+		pos = h.end
+	}
+	return append(lines, base[pos:end]...)
+}
+
+// joinLines は行をつなげます。末尾の改行は、どちらかの側が変えていればその側に合わせます
+func joinLines(lines []string, base, ours, theirs string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	newline := strings.HasSuffix(base, "\n")
+	if o := strings.HasSuffix(ours, "\n"); o != newline {
+		newline = o
+	} else if t := strings.HasSuffix(theirs, "\n"); t != newline {
+		newline = t
+	}
+	text := strings.Join(lines, "\n")
+	if newline {
+		text += "\n"
+	}
+	return text
+}
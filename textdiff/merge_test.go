@@ -0,0 +1,64 @@
+package textdiff
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	markers := Markers{Ours: "ours", Base: "base", Theirs: "theirs"}
+	base := "# 議事録\n- 日時: 4/1\n- 場所: 会議室A\n\n## 決定事項\n- なし\n"
+
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "different lines",
+			ours:   "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			theirs: "# 議事録\n- 日時: 4/1\n- 場所: 会議室A\n\n## 決定事項\n- リリースは来週\n",
+			want:   "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- リリースは来週\n",
+		},
+		{
+			name:   "same change",
+			ours:   "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			theirs: "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			want:   "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+		},
+		{
+			name:   "only theirs",
+			ours:   base,
+			theirs: "# 議事録\n- 日時: 4/1\n- 場所: 会議室B\n\n## 決定事項\n- なし\n",
+			want:   "# 議事録\n- 日時: 4/1\n- 場所: 会議室B\n\n## 決定事項\n- なし\n",
+		},
+		{
+			name:   "conflict",
+			ours:   "# 議事録\n- 日時: 4/2\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			theirs: "# 議事録\n- 日時: 4/3\n- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			want: "# 議事録\n<<<<<<< ours\n- 日時: 4/2\n||||||| base\n- 日時: 4/1\n=======\n- 日時: 4/3\n>>>>>>> theirs\n" +
+				"- 場所: 会議室A\n\n## 決定事項\n- なし\n",
+			conflicts: 1,
+		},
+		{
+			name:      "insertions at the same place",
+			ours:      base + "- 次回: 4/8\n",
+			theirs:    base + "- 次回: 4/15\n",
+			want:      base + "<<<<<<< ours\n- 次回: 4/8\n||||||| base\n=======\n- 次回: 4/15\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(base, tt.ours, tt.theirs, markers)
+			if got.Text != tt.want {
+				t.Errorf("Unexpected merge:\n%s", got.Text)
+			}
+			if len(got.Conflicts) != tt.conflicts {
+				t.Errorf("Expected %d conflicts, but got %+v", tt.conflicts, got.Conflicts)
+			}
+		})
+	}
+}
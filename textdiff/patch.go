@@ -0,0 +1,166 @@
+package textdiff
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader は "@@ -10,4 +10,5 @@" の形式のハンクの見出しです
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// patchHunk は unified 形式の差分の1つのハンクです
+type patchHunk struct {
+	header string
+	// start は見出しに書かれた変更前の開始位置 (0から始まる行番号) です
+	start    int
+	old, new []string
+}
+
+// PatchError は差分を適用できなかった理由です
+type PatchError struct {
+	// Hunk は1から始まるハンクの番号です。差分の形式の誤りでは0です
+	Hunk    int
+	Header  string
+	Message string
+}
+
+func (e *PatchError) Error() string {
+	if e.Hunk == 0 {
+		return "invalid diff: " + e.Message
+	}
+	return fmt.Sprintf("hunk %d (%s) does not apply: %s", e.Hunk, e.Header, e.Message)
+}
+
+// Apply は unified 形式の diff を text に適用します
+// ハンクの変更前の行 (文脈と削除する行) は完全に一致しなければなりません
+// 見出しの位置で一致しない場合は、前のハンクより後ろで一致する箇所が1つだけあればそこに適用します
+func Apply(text, diff string) (string, error) {
+	hunks, err := parsePatch(diff)
+	if err != nil {
+		return "", err
+	}
+
+	lines := SplitLines(text)
+	var out []string
+	pos, offset := 0, 0
+	for i, h := range hunks {
+		at, err := locate(lines, pos, h.start+offset, h.old)
+		if err != nil {
+			return "", &PatchError{Hunk: i + 1, Header: h.header, Message: err.Error()}
+		}
+		out = append(out, lines[pos:at]...)
+		out = append(out, h.new...)
+		pos = at + len(h.old)
+		offset = at - h.start
+	}
+	out = append(out, lines[pos:]...)
+
+	if len(out) == 0 {
+		return "", nil
+	}
+	result := strings.Join(out, "\n")
+	if text == "" || strings.HasSuffix(text, "\n") {
+		result += "\n"
+	}
+	return result, nil
+}
+
+func parsePatch(diff string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	var cur *patchHunk
+	for _, line := range SplitLines(strings.ReplaceAll(diff, "\r\n", "\n")) {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			// 変更前の行数が0のハンクは、start の行の後ろへの挿入を表す
+			if m[2] != "0" && start > 0 {
+				start--
+			}
+			hunks = append(hunks, patchHunk{header: m[0], start: start})
+			cur = &hunks[len(hunks)-1]
+			continue
+		}
+		if cur == nil {
+			// 最初のハンクより前の "--- a/..." などの見出しは読み飛ばす
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "-"):
+			cur.old = append(cur.old, line[1:])
+		case strings.HasPrefix(line, "+"):
+			cur.new = append(cur.new, line[1:])
+		case strings.HasPrefix(line, " "):
+			cur.old = append(cur.old, line[1:])
+			cur.new = append(cur.new, line[1:])
+		case line == "":
+			// 空の文脈の行は先頭の空白が削られていることが多い
+			cur.old = append(cur.old, "")
+			cur.new = append(cur.new, "")
+		default:
+			return nil, &PatchError{Message: fmt.Sprintf("unexpected line %q in hunk %d (lines must start with ' ', '-' or '+')", line, len(hunks))}
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, &PatchError{Message: "no hunks found (each hunk must start with a line such as @@ -1,3 +1,4 @@)"}
+	}
+	// 差分の末尾の空行は、空の文脈の行ではなく差分の終わりとみなす
+	h := &hunks[len(hunks)-1]
+	for len(h.old) > 0 && len(h.new) > 0 && h.old[len(h.old)-1] == "" && h.new[len(h.new)-1] == "" {
+		h.old, h.new = h.old[:len(h.old)-1], h.new[:len(h.new)-1]
+	}
+	return hunks, nil
+}
+
+// locate は lines の from 以降で old と一致する位置を返します。want の位置を優先します
+func locate(lines []string, from, want int, old []string) (int, error) {
+	if want >= from && matchesAt(lines, want, old) {
+		return want, nil
+	}
+
+	var found []int
+	for at := from; at+len(old) <= len(lines); at++ {
+		if matchesAt(lines, at, old) {
+			found = append(found, at)
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1 && len(old) == 0:
+		return 0, fmt.Errorf("an insertion without context lines must be at the position in its header")
+	case len(found) > 1:
+		return 0, fmt.Errorf("the context matches %d places (lines %s); include more context lines", len(found), lineNumbers(found))
+	}
+	return 0, mismatch(lines, want, old)
+}
+
+func matchesAt(lines []string, at int, old []string) bool {
+	return at >= 0 && at+len(old) <= len(lines) && slices.Equal(lines[at:at+len(old)], old)
+}
+
+// mismatch は want の位置で最初に一致しなかった行を説明するエラーを返します
+func mismatch(lines []string, want int, old []string) error {
+	for i, expected := range old {
+		n := want + i
+		if n < 0 || n >= len(lines) {
+			return fmt.Errorf("line %d is past the end of the text (%d lines), but the diff expects %q", n+1, len(lines), expected)
+		}
+		if lines[n] != expected {
+			return fmt.Errorf("line %d is %q, but the diff expects %q, and the lines do not match anywhere else. Get the current body and make the diff again", n+1, lines[n], expected)
+		}
+	}
+	return fmt.Errorf("the lines do not match the text")
+}
+
+func lineNumbers(positions []int) string {
+	numbers := make([]string, 0, len(positions))
+	for _, p := range positions {
+		numbers = append(numbers, strconv.Itoa(p+1))
+	}
+	return strings.Join(numbers, ", ")
+}
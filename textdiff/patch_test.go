@@ -0,0 +1,61 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	text := "# 手順\n1. clone\n2. build\n3. test\n4. deploy\n"
+
+	tests := []struct {
+		name    string
+		diff    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "at the header position",
+			diff: "--- a\n+++ b\n@@ -3,2 +3,3 @@\n 2. build\n+2.5. lint\n 3. test\n",
+			want: "# 手順\n1. clone\n2. build\n2.5. lint\n3. test\n4. deploy\n",
+		},
+		{
+			name: "with a wrong line number",
+			diff: "@@ -10,2 +10,2 @@\n 3. test\n-4. deploy\n+4. deploy to staging\n",
+			want: "# 手順\n1. clone\n2. build\n3. test\n4. deploy to staging\n",
+		},
+		{
+			name: "round trip",
+			diff: Unified(text, "# 手順\n1. clone the repository\n2. build\n3. test\n", "a", "b", 1),
+			want: "# 手順\n1. clone the repository\n2. build\n3. test\n",
+		},
+		{
+			name:    "context does not match",
+			diff:    "@@ -3,2 +3,2 @@\n 2. make\n-3. test\n+3. test all\n",
+			wantErr: `hunk 1 (@@ -3,2 +3,2 @@) does not apply: line 3 is "2. build", but the diff expects "2. make"`,
+		},
+		{
+			name:    "no hunks",
+			diff:    "-3. test\n+3. test all\n",
+			wantErr: "invalid diff: no hunks found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(text, tt.diff)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, but got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unexpected result:\n%s", got)
+			}
+		})
+	}
+}
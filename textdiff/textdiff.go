@@ -0,0 +1,249 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind は編集の種類です
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Edit は行単位の編集です
+// Equal と Delete は A の行、Insert は B の行を表します。行番号は0から始まります
+type Edit struct {
+	Kind Kind
+	A, B int // Equal では両方、Delete では A、Insert では B の行番号
+	Line string
+}
+
+// SplitLines は text を改行で分割します。末尾の改行は空の行として扱いません
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// maxCost は middleSnake で探す編集の数の上限です
+// これを超えるほど異なる場合は最短の編集をあきらめ、それまでに最も進んだ位置で分割します
+const maxCost = 1024
+
+// Lines は a を b に変える編集を Myers のアルゴリズムで求めます
+// 中央のスネークで分割する線形空間の方法を使うため、メモリは行数に比例します
+// 大きく異なる場合は最短とは限りません
+func Lines(a, b []string) []Edit {
+	size := len(a) + len(b) + 1
+	d := differ{a: a, b: b, vf: make([]int, 2*size+1), vb: make([]int, 2*size+1), offset: size}
+	d.compare(0, len(a), 0, len(b))
+	return d.edits
+}
+
+type differ struct {
+	a, b  []string
+	edits []Edit
+	// vf と vb は前からと後ろからの探索で、対角線ごとに最も進んだ位置です
+	vf, vb []int
+	offset int
+}
+
+// compare は a[aLo:aHi] を b[bLo:bHi] に変える編集を追加します
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 先頭と末尾の共通の行は、探索せずに一致として扱う
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, Edit{Kind: Equal, A: aLo, B: bLo, Line: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			d.edits = append(d.edits, Edit{Kind: Insert, A: aLo, B: y, Line: d.b[y]})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			d.edits = append(d.edits, Edit{Kind: Delete, A: x, B: bLo, Line: d.a[x]})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.edits = append(d.edits, Edit{Kind: Equal, A: x, B: y, Line: d.a[x]})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.edits = append(d.edits, Edit{Kind: Equal, A: aHi + i, B: bHi + i, Line: d.a[aHi+i]})
+	}
+}
+
+// middleSnake は a[aLo:aHi] を b[bLo:bHi] に変える最短の編集の中央にある一致の範囲 (x, y) から (u, v) を返します
+// 前からと後ろからの探索が重なったところが中央です
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	vf, vb, off := d.vf, d.vb, d.offset
+	vf[off+1], vb[off+1] = 0, 0
+
+	for cost := 0; cost <= (n+m+1)/2; cost++ {
+		// 前から探す。後ろからの探索は、前の手数で対角線 delta-k に届いている
+		for k := -cost; k <= cost; k += 2 {
+			var x int
+			if k == -cost || (k != cost && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if odd && delta-k >= -(cost-1) && delta-k <= cost-1 && x+vb[off+delta-k] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+
+		// 後ろから探す。位置は末尾からの行数で表す
+		for k := -cost; k <= cost; k += 2 {
+			var x int
+			if k == -cost || (k != cost && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if !odd && delta-k >= -cost && delta-k <= cost && x+vf[off+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+
+		if cost >= maxCost {
+			// 前からの探索で最も進んだ位置で分割する。編集は最短でなくなるが、手数が限られる
+			best, bestK := -1, 0
+			for k := -cost; k <= cost; k += 2 {
+				x := min(vf[off+k], n)
+				if y := x - k; y >= 0 && y <= m && x+y > best {
+					best, bestK = x+y, k
+				}
+			}
+			x := min(vf[off+bestK], n)
+			return aLo + x, bLo + x - bestK, aLo + x, bLo + x - bestK
+		}
+	}
+	// 前からと後ろからの探索は必ず (n+m+1)/2 手までに重なる
+	panic("textdiff: middle snake not found")
+}
+
+// Unified は from を to に変える差分を unified 形式で返します。差分がない場合は空文字列を返します
+// context は変更の前後に表示する変更のない行の数です
+func Unified(from, to, fromName, toName string, context int) string {
+	edits := Lines(SplitLines(from), SplitLines(to))
+
+	changed := false
+	for _, e := range edits {
+		if e.Kind != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	for i := 0; i < len(edits); {
+		// 次の変更を探し、前後の context 行を含む範囲を1つのハンクにする
+		for i < len(edits) && edits[i].Kind == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].Kind != Equal {
+				end++
+				continue
+			}
+			// 変更のない行が 2*context 行より長く続けば、ハンクを区切る
+			run := end
+			for run < len(edits) && edits[run].Kind == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end = min(end+context, len(edits))
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&b, edits[start:end])
+		i = end
+	}
+	return b.String()
+}
+
+func writeHunk(b *strings.Builder, edits []Edit) {
+	aStart, bStart := edits[0].A, edits[0].B
+	aLen, bLen := 0, 0
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			aLen++
+			bLen++
+		case Delete:
+			aLen++
+		case Insert:
+			bLen++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			b.WriteString(" ")
+		case Delete:
+			b.WriteString("-")
+		case Insert:
+			b.WriteString("+")
+		}
+		b.WriteString(e.Line)
+		b.WriteString("\n")
+	}
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
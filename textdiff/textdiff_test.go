@@ -0,0 +1,126 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestUnified(t *testing.T) {
+	from := "# 手順\n1. clone\n2. build\n3. test\n4. deploy\n5. notify\n6. done\n7. a\n8. b\n9. c\n10. d\n"
+	to := "# 手順\n1. clone\n2. build\n3. test\n4. deploy to staging\n5. notify\n6. done\n7. a\n8. b\n9. c\n10. d\n11. e\n"
+
+	want := `--- before
++++ after
+@@ -3,5 +3,5 @@
+ 2. build
+ 3. test
+-4. deploy
++4. deploy to staging
+ 5. notify
+ 6. done
+@@ -10,2 +10,3 @@
+ 9. c
+ 10. d
++11. e
+`
+	if got := Unified(from, to, "before", "after", 2); got != want {
+		t.Errorf("Unexpected diff:\n%s", got)
+	}
+
+	if got := Unified(from, from, "before", "after", 3); got != "" {
+		t.Errorf("Expected no diff for the same text, but got:\n%s", got)
+	}
+
+	if got := Unified("", "new\n", "before", "after", 3); got != "--- before\n+++ after\n@@ -0,0 +1 @@\n+new\n" {
+		t.Errorf("Unexpected diff for a new text:\n%s", got)
+	}
+}
+
+// checkEdits は edits が a を b に変える正しい編集で、行番号が続いていることを確かめ、変更の数を返します
+func checkEdits(t *testing.T, a, b []string, edits []Edit) int {
+	t.Helper()
+	x, y, changes := 0, 0, 0
+	for i, e := range edits {
+		if e.A != x || e.B != y {
+			t.Fatalf("Edit %d is at (%d, %d), but expected (%d, %d)", i, e.A, e.B, x, y)
+		}
+		switch e.Kind {
+		case Equal:
+			if a[x] != e.Line || b[y] != e.Line {
+				t.Fatalf("Edit %d keeps %q, but the lines are %q and %q", i, e.Line, a[x], b[y])
+			}
+			x, y = x+1, y+1
+		case Delete:
+			if a[x] != e.Line {
+				t.Fatalf("Edit %d deletes %q, but the line is %q", i, e.Line, a[x])
+			}
+			x, changes = x+1, changes+1
+		case Insert:
+			if b[y] != e.Line {
+				t.Fatalf("Edit %d inserts %q, but the line is %q", i, e.Line, b[y])
+			}
+			y, changes = y+1, changes+1
+		}
+	}
+	if x != len(a) || y != len(b) {
+		t.Fatalf("The edits end at (%d, %d), but expected (%d, %d)", x, y, len(a), len(b))
+	}
+	return changes
+}
+
+func TestLinesIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+
+		// 最長共通部分列の長さから、最短の編集の数を求める
+		lcs := make([][]int, len(a)+1)
+		for x := range lcs {
+			lcs[x] = make([]int, len(b)+1)
+		}
+		for x := len(a) - 1; x >= 0; x-- {
+			for y := len(b) - 1; y >= 0; y-- {
+				if a[x] == b[y] {
+					lcs[x][y] = lcs[x+1][y+1] + 1
+				} else {
+					lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+				}
+			}
+		}
+
+		want := len(a) + len(b) - 2*lcs[0][0]
+		if got := checkEdits(t, a, b, Lines(a, b)); got != want {
+			t.Fatalf("Expected %d changes from %q to %q, but got %d", want, a, b, got)
+		}
+	}
+}
+
+func TestLinesLargeRewrite(t *testing.T) {
+	// 編集の数が maxCost を大きく超える書き換えでも、時間とメモリが限られることを確かめる
+	var a, b []string
+	for i := 0; i < 10000; i++ {
+		a = append(a, fmt.Sprintf("old line %d", i))
+		b = append(b, fmt.Sprintf("new line %d", i))
+		if i%100 == 0 {
+			a = append(a, "shared")
+			b = append(b, "shared")
+		}
+	}
+
+	start := time.Now()
+	edits := Lines(a, b)
+	checkEdits(t, a, b, edits)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the diff to finish quickly, but it took %s", elapsed)
+	}
+}
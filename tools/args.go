@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ArgumentError は1つの引数の誤りを表します
+type ArgumentError struct {
+	Name    string
+	Message string
+}
+
+func (e ArgumentError) Error() string {
+	return fmt.Sprintf("%s %s", e.Name, e.Message)
+}
+
+// ArgumentErrors は引数の誤りの一覧です。すべての誤りをまとめて返し、モデルが一度で直せるようにします
+type ArgumentErrors []ArgumentError
+
+func (e ArgumentErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// bindArguments はツールの引数を dst の構造体に設定します
+//
+// フィールドはタグで引数と対応付けます
+//
+//	PostID int64    `arg:"post_id,required" min:"1"`
+//	Page   int      `arg:"page" default:"1" min:"1"`
+//	Scope  string   `arg:"scope" enum:"everyone,group,private"`
+//	Tags   []string `arg:"tags"`
+//	Text   string   `arg:"text,verbatim"`
+//
+// 空白だけの文字列は省略されたものとして扱います。verbatim の文字列は空白だけでも、空文字列でもそのまま設定します
+// 数値は JSON の数値と数値の文字列、真偽値は true/false と "true"/"false"、
+// リストは配列とカンマ区切りの文字列のどちらでも受け付けます
+// ポインタのフィールドは引数が指定された場合だけ設定します
+func bindArguments(args map[string]interface{}, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	var errs ArgumentErrors
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		var required, verbatim bool
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "required":
+				required = true
+			case "verbatim":
+				verbatim = true
+			}
+		}
+
+		raw, present := args[name]
+		if present && (raw == nil || !verbatim && isEmptyArgument(raw)) {
+			present = false
+		}
+		if !present {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				raw, present = def, true
+			}
+		}
+		if !present {
+			if required {
+				errs = append(errs, ArgumentError{Name: name, Message: "is required"})
+			}
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+
+		if err := setArgument(fv, raw); err != nil {
+			errs = append(errs, ArgumentError{Name: name, Message: err.Error()})
+			continue
+		}
+		if err := validateArgument(fv, field.Tag); err != nil {
+			errs = append(errs, ArgumentError{Name: name, Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// isEmptyArgument は null や空文字列のように、省略されたものとして扱う値かを返します
+func isEmptyArgument(raw interface{}) bool {
+	switch v := raw.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	}
+	return false
+}
+
+// argumentDecoder は独自の形式で引数を受け取る型が実装します
+type argumentDecoder interface {
+	decodeArgument(raw interface{}) error
+}
+
+func setArgument(fv reflect.Value, raw interface{}) error {
+	if fv.CanAddr() {
+		if d, ok := fv.Addr().Interface().(argumentDecoder); ok {
+			return d.decodeArgument(raw)
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		fv.SetString(s)
+	case reflect.Int, reflect.Int64:
+		n, err := toInt(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		b, err := toBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Slice:
+		items, err := toList(raw)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(fv.Type(), 0, len(items))
+		for i, item := range items {
+			ev := reflect.New(fv.Type().Elem()).Elem()
+			if err := setArgument(ev, item); err != nil {
+				return fmt.Errorf("item #%d %s", i+1, err)
+			}
+			slice = reflect.Append(slice, ev)
+		}
+		fv.Set(slice)
+	default:
+		panic(fmt.Sprintf("unsupported argument type: %s", fv.Type()))
+	}
+	return nil
+}
+
+func toInt(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("must be an integer (got %v)", v)
+		}
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case json.Number:
+		return toInt(v.String())
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("must be an integer (got %q)", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("must be an integer")
+}
+
+func toBool(raw interface{}) (bool, error) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("must be true or false (got %q)", v)
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("must be true or false")
+}
+
+// toList は配列かカンマ区切りの文字列を要素の一覧にします
+func toList(raw interface{}) ([]interface{}, error) {
+	switch v := raw.(type) {
+	case []interface{}:
+		return v, nil
+	case []string:
+		items := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			items = append(items, s)
+		}
+		return items, nil
+	case string:
+		var items []interface{}
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+		return items, nil
+	case float64, int, int64:
+		return []interface{}{v}, nil
+	}
+	return nil, fmt.Errorf("must be an array")
+}
+
+// validateArgument は min、max、enum タグの条件を確認します
+func validateArgument(fv reflect.Value, tag reflect.StructTag) error {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int64:
+		n := fv.Int()
+		if min, ok := tag.Lookup("min"); ok {
+			if m, _ := strconv.ParseInt(min, 10, 64); n < m {
+				return fmt.Errorf("must be at least %d (got %d)", m, n)
+			}
+		}
+		if max, ok := tag.Lookup("max"); ok {
+			if m, _ := strconv.ParseInt(max, 10, 64); n > m {
+				return fmt.Errorf("must be at most %d (got %d)", m, n)
+			}
+		}
+	case reflect.String:
+		if enum, ok := tag.Lookup("enum"); ok {
+			values := strings.Split(enum, ",")
+			for _, value := range values {
+				if fv.String() == value {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of '%s' (got %q)", strings.Join(values, "', '"), fv.String())
+		}
+	case reflect.Slice:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateArgument(fv.Index(i), tag); err != nil {
+				return fmt.Errorf("item #%d %s", i+1, err)
+			}
+		}
+	}
+	return nil
+}
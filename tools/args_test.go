@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBindArguments(t *testing.T) {
+	type args struct {
+		PostID int64    `arg:"post_id,required" min:"1"`
+		Page   int      `arg:"page" default:"1" min:"1"`
+		Draft  *bool    `arg:"draft"`
+		Tags   []string `arg:"tags"`
+		Groups []int    `arg:"groups"`
+		Scope  string   `arg:"scope" enum:"everyone,group,private"`
+	}
+
+	draft := true
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  args
+	}{
+		{
+			name:  "json types",
+			input: map[string]interface{}{"post_id": float64(123), "draft": true, "tags": []interface{}{"a, b", "c"}, "groups": []interface{}{float64(1), "2"}},
+			want:  args{PostID: 123, Page: 1, Draft: &draft, Tags: []string{"a, b", "c"}, Groups: []int{1, 2}},
+		},
+		{
+			name:  "string forms",
+			input: map[string]interface{}{"post_id": "123", "page": "2", "draft": "true", "tags": "a, b,c", "groups": "1,2", "scope": "group"},
+			want:  args{PostID: 123, Page: 2, Draft: &draft, Tags: []string{"a", "b", "c"}, Groups: []int{1, 2}, Scope: "group"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got args
+			if err := bindArguments(tt.input, &got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, but got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// すべての誤りをまとめて返す
+	var got args
+	err := bindArguments(map[string]interface{}{"page": float64(0), "groups": "1,x", "scope": "public"}, &got)
+	errs, ok := err.(ArgumentErrors)
+	if !ok {
+		t.Fatalf("Expected ArgumentErrors, but got %v", err)
+	}
+	for _, want := range []string{"post_id is required", "page must be at least 1", "groups item #2 must be an integer", "scope must be one of"} {
+		if !strings.Contains(errs.Error(), want) {
+			t.Errorf("Expected %q to contain %q", errs.Error(), want)
+		}
+	}
+}
+
+func TestBindVerbatimArguments(t *testing.T) {
+	type args struct {
+		Pattern     string `arg:"pattern,required,verbatim"`
+		Replacement string `arg:"replacement,verbatim"`
+		Section     string `arg:"section"`
+	}
+
+	var got args
+	err := bindArguments(map[string]interface{}{"pattern": "  ", "replacement": " ", "section": "  "}, &got)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (args{Pattern: "  ", Replacement: " "}); got != want {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+
+	// null は verbatim でも省略と同じ
+	err = bindArguments(map[string]interface{}{"pattern": nil, "replacement": ""}, &got)
+	if err == nil || !strings.Contains(err.Error(), "pattern is required") {
+		t.Errorf("Expected an error for a null pattern, but got %v", err)
+	}
+}
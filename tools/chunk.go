@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/markdown"
+)
+
+// DefaultMaxBodyChars は get_post_by_post_id が一度に返す本文の既定の最大文字数です
+const DefaultMaxBodyChars = 20000
+
+var maxBodyChars atomic.Int64
+
+func init() {
+	maxBodyChars.Store(DefaultMaxBodyChars)
+}
+
+// SetMaxBodyChars は get_post_by_post_id が一度に返す本文の最大文字数を登録します
+// これより長い本文は分割し、続きはカーソルで読みます。起動時に一度呼び出します
+func SetMaxBodyChars(n int) {
+	maxBodyChars.Store(int64(n))
+}
+
+// bodyCursor は分割した本文の続きを読むためのカーソルです
+// 投稿が更新されると分割の位置が変わるため、ページ番号ではなく、分割したときの updated_at を含めたカーソルで読みます
+type bodyCursor struct {
+	updatedAt int64
+	// start と end は分割して読んでいる本文の範囲 (バイト) です
+	start, end int
+	chunk      int
+	maxChars   int
+}
+
+func (c bodyCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d:%d:%d:%d", c.updatedAt, c.start, c.end, c.chunk, c.maxChars)))
+}
+
+func (c *bodyCursor) decodeArgument(raw interface{}) error {
+	s, _ := raw.(string)
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err == nil {
+		_, err = fmt.Sscanf(string(b), "%d:%d:%d:%d:%d", &c.updatedAt, &c.start, &c.end, &c.chunk, &c.maxChars)
+	}
+	if err != nil || c.start < 0 || c.start > c.end || c.chunk < 0 || c.maxChars < 1 {
+		return errors.New("is not a cursor returned by get_post_by_post_id")
+	}
+	return nil
+}
+
+// chunkMeta は分割した本文のどの部分を返したかを、search_posts の meta と同じ形で表します
+// ページの番号は1から始まり、前後の部分はページの番号の代わりにカーソルで読みます
+type chunkMeta struct {
+	Page           int    `json:"page"`
+	PreviousPage   *int   `json:"previous_page"`
+	NextPage       *int   `json:"next_page"`
+	Total          int    `json:"total"`
+	PreviousCursor string `json:"previous_cursor,omitempty"`
+	NextCursor     string `json:"next_cursor,omitempty"`
+}
+
+// chunkedBody は本文の [start, end) の範囲の cursor の位置から最大 maxChars 文字を、前後の部分を読むためのカーソルつきで返します
+// cursor が nil の場合は最初の部分を返し、cursor がある場合は start と end の代わりにカーソルの範囲を読みます
+// 範囲が maxChars 文字以下の場合は、分割せずに whole の書式で返します
+func chunkedBody(post *docbase.GetPostResponse, cursor *bodyCursor, start, end, maxChars int, whole string) (string, error) {
+	chunk := 0
+	if cursor != nil {
+		if cursor.updatedAt != post.UpdatedAt.Unix() {
+			return "", fmt.Errorf("post %d was updated at %s after this cursor was issued. Read it again from the start without cursor",
+				post.PostID, post.UpdatedAt.Format(time.RFC3339))
+		}
+		boundary := func(i int) bool { return i == len(post.Body) || utf8.RuneStart(post.Body[i]) }
+		if cursor.end > len(post.Body) || !boundary(cursor.start) || !boundary(cursor.end) {
+			return "", fmt.Errorf("the cursor does not match post %d. Read it again from the start without cursor", post.PostID)
+		}
+		// カーソルはクライアントから渡されるため、サーバーの上限を超える文字数では読ませない
+		start, end, chunk, maxChars = cursor.start, cursor.end, cursor.chunk, min(maxChars, cursor.maxChars)
+	}
+
+	body := post.Body[start:end]
+	starts := markdown.Chunks(body, maxChars)
+	if chunk >= len(starts) {
+		return "", fmt.Errorf("the cursor points past the end of post %d", post.PostID)
+	}
+	if len(starts) == 1 && cursor == nil {
+		return fmt.Sprintf(whole, body), nil
+	}
+
+	from, to := start+starts[chunk], end
+	if chunk+1 < len(starts) {
+		to = start + starts[chunk+1]
+	}
+	text := post.Body[from:to]
+	cursorAt := func(i int) string {
+		return bodyCursor{updatedAt: post.UpdatedAt.Unix(), start: start, end: end, chunk: i, maxChars: maxChars}.String()
+	}
+
+	var b strings.Builder
+	charStart := utf8.RuneCountInString(post.Body[:from])
+	fmt.Fprintf(&b, "Body (part %d of %d, characters %d-%d of %d, lines %d-%d):\n%s",
+		chunk+1, len(starts), charStart, charStart+utf8.RuneCountInString(text)-1, utf8.RuneCountInString(post.Body),
+		markdown.LineAt(post.Body, from), lastLine(post.Body, from, to), text)
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\n")
+	}
+	meta := chunkMeta{Page: chunk + 1, Total: len(starts)}
+	if chunk > 0 {
+		meta.PreviousPage, meta.PreviousCursor = &chunk, cursorAt(chunk-1)
+	}
+	if chunk+1 < len(starts) {
+		next := chunk + 2
+		meta.NextPage, meta.NextCursor = &next, cursorAt(chunk+1)
+	}
+	m, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&b, "Meta: %s\n", m)
+	if meta.NextPage != nil {
+		fmt.Fprintf(&b, "Call get_post_by_post_id again with only post_id and cursor set to next_cursor to read part %d.\n", *meta.NextPage)
+	} else {
+		b.WriteString("This is the last part.\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withTeam はすべてのツールに共通の team 引数を定義します
+func withTeam() mcp.ToolOption {
+	return mcp.WithString(
+		"team",
+		mcp.Description("The name of the DocBase team to use (default is the configured default team, see list_teams)"),
+	)
+}
+
+// newClient は team 引数で指定されたチームのクライアントを作成します
+func newClient(ctx context.Context, request mcp.CallToolRequest) (*docbase.DocBaseClient, error) {
+	team, _ := request.Params.Arguments["team"].(string)
+	return docbase.NewClient(ctx, team)
+}
+
+// teamPolicy は team 引数で指定されたチームの投稿のポリシーを返します
+func teamPolicy(request mcp.CallToolRequest) (docbase.Policy, error) {
+	teams, err := docbase.ConfiguredTeams()
+	if err != nil {
+		return docbase.Policy{}, err
+	}
+
+	name, _ := request.Params.Arguments["team"].(string)
+	team, err := teams.Get(name)
+	if err != nil {
+		return docbase.Policy{}, err
+	}
+	return team.Policy, nil
+}
+
+// formatPostResult は投稿を作成、更新したときの結果に、実際に適用された公開範囲と通知の有無を含めます
+func formatPostResult(message string, post *docbase.GetPostResponse, notice bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nTitle: %s\nID: %d\nScope: %s", message, post.Title, post.PostID, post.Scope)
+	if len(post.Groups) > 0 {
+		names := make([]string, 0, len(post.Groups))
+		for _, g := range post.Groups {
+			names = append(names, fmt.Sprintf("%s (%d)", g.Name, g.ID))
+		}
+		fmt.Fprintf(&b, "\nGroups: %s", strings.Join(names, ", "))
+	}
+	fmt.Fprintf(&b, "\nDraft: %t\nNotice: %t", post.Draft, notice)
+	if !post.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "\nUpdated at: %s", post.UpdatedAt.Format(time.RFC3339))
+	}
+	if post.URL != "" {
+		fmt.Fprintf(&b, "\nURL: %s", post.URL)
+	}
+	return b.String()
+}
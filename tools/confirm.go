@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// confirmationTTL は確認トークンの有効期間です
+const confirmationTTL = 10 * time.Minute
+
+var confirmations = &confirmationStore{tokens: make(map[string]confirmation)}
+
+// confirmationStore は発行した確認トークンと、それを使える呼び出しの組を保持します
+type confirmationStore struct {
+	mu     sync.Mutex
+	tokens map[string]confirmation
+}
+
+type confirmation struct {
+	fingerprint string
+	expiresAt   time.Time
+}
+
+// issue は fingerprint の呼び出しに使える確認トークンを発行します
+func (s *confirmationStore) issue(fingerprint string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	s.tokens[token] = confirmation{fingerprint: fingerprint, expiresAt: time.Now().Add(confirmationTTL)}
+	return token
+}
+
+// consume は token が fingerprint の呼び出しに対して発行された有効なトークンであれば、使用済みにして true を返します
+func (s *confirmationStore) consume(token, fingerprint string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	c, ok := s.tokens[token]
+	if !ok || c.fingerprint != fingerprint {
+		return false
+	}
+	delete(s.tokens, token)
+	return true
+}
+
+func (s *confirmationStore) prune() {
+	now := time.Now()
+	for token, c := range s.tokens {
+		if now.After(c.expiresAt) {
+			delete(s.tokens, token)
+		}
+	}
+}
+
+// requestFingerprint はツールの名前と confirmation_token 以外の引数から、呼び出しを識別する値を作ります
+// 確認した内容と異なる引数でトークンが使われないようにするためのものです
+func requestFingerprint(request mcp.CallToolRequest) string {
+	args := make(map[string]interface{}, len(request.Params.Arguments))
+	for k, v := range request.Params.Arguments {
+		if k != "confirmation_token" {
+			args[k] = v
+		}
+	}
+	// map のキーは順に並べて出力されるため、同じ引数からは同じ値になる
+	b, _ := json.Marshal(map[string]interface{}{"tool": request.Params.Name, "arguments": args})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// withConfirmationToken は公開範囲を広げる操作を確定するための confirmation_token 引数を定義します
+func withConfirmationToken() mcp.ToolOption {
+	return mcp.WithString(
+		"confirmation_token",
+		mcp.Description("The token returned when this call widens who can read the post or sends a notification. "+
+			"Pass it only after the user has confirmed, together with exactly the same arguments"),
+	)
+}
+
+// requireConfirmation は reasons がある場合に、ユーザーの確認を求める結果を返します
+// 同じ引数で発行された有効な confirmation_token が指定されていれば nil を返し、操作を続けさせます
+func requireConfirmation(request mcp.CallToolRequest, reasons []string) *mcp.CallToolResult {
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	fingerprint := requestFingerprint(request)
+	if token, _ := request.Params.Arguments["confirmation_token"].(string); token != "" {
+		if confirmations.consume(token, fingerprint) {
+			return nil
+		}
+		return newToolResultError("confirmation_token is invalid, expired, or was issued for different arguments. " +
+			"Call again without confirmation_token to get a new one.")
+	}
+
+	token := confirmations.issue(fingerprint)
+	return mcp.NewToolResultText(fmt.Sprintf("Confirmation required. No changes were made.\n"+
+		"This call would:\n- %s\n"+
+		"Ask the user to confirm this. Once they do, call %s again with exactly the same arguments plus confirmation_token %q (valid for %s).",
+		strings.Join(reasons, "\n- "), request.Params.Name, token, confirmationTTL))
+}
+
+// visibilityChanges は公開範囲を広げる変更と通知を、ユーザーに確認してもらう内容として返します
+// 作成の場合は from に非公開を渡します
+func visibilityChanges(from docbase.Scope, fromGroups []int, to docbase.Scope, toGroups []int, notice bool) []string {
+	var reasons []string
+	switch {
+	case to == docbase.ScopeAll && docbase.Widens(from, to):
+		reasons = append(reasons, "make the post visible to everyone in the team")
+	case to == docbase.ScopeGroup && (from == docbase.ScopeGroup || docbase.Widens(from, to)):
+		// グループ間の変更では、新しく加わったグループだけを確認する
+		current := make(map[int]bool, len(fromGroups))
+		if from == docbase.ScopeGroup {
+			for _, id := range fromGroups {
+				current[id] = true
+			}
+		}
+		var added []string
+		for _, id := range toGroups {
+			if !current[id] {
+				added = append(added, strconv.Itoa(id))
+			}
+		}
+		if len(added) > 0 {
+			reasons = append(reasons, fmt.Sprintf("make the post visible to the groups with IDs %s", strings.Join(added, ", ")))
+		}
+	}
+	if notice {
+		reasons = append(reasons, "send a notification to the readers of the post")
+	}
+	return reasons
+}
+
+// draftPublication は下書きを公開する変更を、ユーザーに確認してもらう内容として返します
+// 下書きは作成者しか読めないため、非公開でない投稿の下書きをやめると公開範囲の全員が読めるようになります
+func draftPublication(wasDraft bool, draft *bool, scope docbase.Scope) []string {
+	if !wasDraft || draft == nil || *draft {
+		return nil
+	}
+	switch scope {
+	case docbase.ScopeAll:
+		return []string{"publish the draft to everyone in the team"}
+	case docbase.ScopeGroup:
+		return []string{"publish the draft to the groups of the post"}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestVisibilityChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       docbase.Scope
+		fromGroups []int
+		to         docbase.Scope
+		toGroups   []int
+		notice     bool
+		want       int
+	}{
+		{"private post", docbase.ScopePrivate, nil, docbase.ScopePrivate, nil, false, 0},
+		{"publish", docbase.ScopeGroup, []int{1}, docbase.ScopeAll, nil, false, 1},
+		{"narrow", docbase.ScopeAll, nil, docbase.ScopeGroup, []int{1}, false, 0},
+		{"same groups", docbase.ScopeGroup, []int{1, 2}, docbase.ScopeGroup, []int{2}, false, 0},
+		{"new group", docbase.ScopeGroup, []int{1}, docbase.ScopeGroup, []int{1, 3}, false, 1},
+		{"notice", docbase.ScopePrivate, nil, docbase.ScopeGroup, []int{1}, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := visibilityChanges(tt.from, tt.fromGroups, tt.to, tt.toGroups, tt.notice)
+			if len(reasons) != tt.want {
+				t.Errorf("Expected %d reasons, but got %v", tt.want, reasons)
+			}
+		})
+	}
+}
+
+func TestDraftPublication(t *testing.T) {
+	published, draft := false, true
+	tests := []struct {
+		name     string
+		wasDraft bool
+		draft    *bool
+		scope    docbase.Scope
+		want     int
+	}{
+		{"publish to everyone", true, &published, docbase.ScopeAll, 1},
+		{"publish to groups", true, &published, docbase.ScopeGroup, 1},
+		{"publish private", true, &published, docbase.ScopePrivate, 0},
+		{"draft unchanged", true, nil, docbase.ScopeAll, 0},
+		{"still a draft", true, &draft, docbase.ScopeAll, 0},
+		{"already published", false, &published, docbase.ScopeAll, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := draftPublication(tt.wasDraft, tt.draft, tt.scope)
+			if len(reasons) != tt.want {
+				t.Errorf("Expected %d reasons, but got %v", tt.want, reasons)
+			}
+		})
+	}
+}
+
+func TestRequireConfirmation(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "create_post"
+	request.Params.Arguments = map[string]interface{}{"title": "title", "body": "body", "scope": "everyone"}
+
+	// 最初の呼び出しでは投稿せずに確認トークンを返す
+	result, err := handleCreatePostRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if !strings.Contains(text, "No changes were made") || !strings.Contains(text, "visible to everyone") {
+		t.Fatalf("Expected a confirmation request, but got %q", text)
+	}
+	token := regexp.MustCompile(`confirmation_token "([0-9a-f]+)"`).FindStringSubmatch(text)[1]
+
+	// 引数を変えた呼び出しにはトークンを使えない
+	other := mcp.CallToolRequest{}
+	other.Params.Name = "create_post"
+	other.Params.Arguments = map[string]interface{}{"title": "other", "body": "body", "scope": "everyone", "confirmation_token": token}
+	if result := requireConfirmation(other, []string{"publish"}); result == nil || !result.IsError {
+		t.Error("Expected the token to be rejected for different arguments")
+	}
+
+	request.Params.Arguments["confirmation_token"] = token
+	if result := requireConfirmation(request, []string{"publish"}); result != nil {
+		t.Errorf("Expected the token to confirm the call, but got %q", resultText(t, result))
+	}
+
+	// トークンは一度しか使えない
+	if result := requireConfirmation(request, []string{"publish"}); result == nil || !result.IsError {
+		t.Error("Expected a used token to be rejected")
+	}
+}
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/textdiff"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// timestamp は RFC 3339 形式の日時の引数です
+type timestamp struct {
+	time.Time
+}
+
+func (ts *timestamp) decodeArgument(raw interface{}) error {
+	s, ok := raw.(string)
+	if !ok {
+		return errors.New("must be a date and time such as 2024-01-02T15:04:05+09:00")
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("must be a date and time such as 2024-01-02T15:04:05+09:00 (got %q)", s)
+	}
+	ts.Time = t
+	return nil
+}
+
+// withExpectedUpdatedAt は投稿が読んだときから変わっていないことを確かめる expected_updated_at 引数を定義します
+func withExpectedUpdatedAt() mcp.ToolOption {
+	return mcp.WithString(
+		"expected_updated_at",
+		mcp.Description("The 'Updated at' value of the post when you read it (from get_post_by_post_id or the previous update). "+
+			"If the post has been changed since then, your changes are merged with that change line by line; "+
+			"if they overlap, nothing is updated and the result shows the conflicts. Strongly recommended when changing the body"),
+	)
+}
+
+// conflictResult は expected の後に投稿が更新されていて、マージの基準にする expected の版がわからない場合に、更新を中止した結果を返します
+// body は更新しようとした本文で、空でなければ今の本文との差分を添えます
+func conflictResult(current *docbase.GetPostResponse, expected time.Time, body string) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conflict: post %d was updated at %s, after the version you read (%s). "+
+		"That version is not known to this server, so your changes could not be merged. Nothing was changed.\n",
+		current.PostID, current.UpdatedAt.Format(time.RFC3339), expected.Format(time.RFC3339))
+	if body != "" {
+		if diff := textdiff.Unified(current.Body, body, fmt.Sprintf("post %d (current)", current.PostID), "your body", 3); diff != "" {
+			fmt.Fprintf(&b, "Diff from the current body to the body you sent:\n%s", diff)
+		}
+	}
+	fmt.Fprintf(&b, "Get the post again with get_post_by_post_id, apply your changes to the current body, "+
+		"and call update_post with expected_updated_at %s.", current.UpdatedAt.Format(time.RFC3339))
+	return newToolResultError("%s", b.String())
+}
+
+// mergeUpdate は読んだときの版 base から今の投稿 current までの変更と、update の変更を3方向でマージします
+// マージできた場合は update のタイトルと本文を書き換えて報告を返し、衝突した場合は更新を中止する結果を返します
+func mergeUpdate(current *docbase.GetPostResponse, base postVersion, expected time.Time, update *docbase.UpdatePostParam) (string, *mcp.CallToolResult) {
+	var conflicts []string
+
+	// タイトルは1行として扱う。変えていないタイトルが送られた場合は、相手の変更を残す
+	switch {
+	case update.Title == "" || update.Title == current.Title:
+	case update.Title == base.Title:
+		update.Title = current.Title
+	case current.Title != base.Title:
+		conflicts = append(conflicts, fmt.Sprintf("title:\n  yours:   %s\n  current: %s", update.Title, current.Title))
+	}
+
+	var merged textdiff.MergeResult
+	if update.Body != "" {
+		merged = textdiff.Merge(base.Body, update.Body, current.Body, textdiff.Markers{
+			Ours:   "your body",
+			Base:   "the version you read (" + expected.Format(time.RFC3339) + ")",
+			Theirs: fmt.Sprintf("post %d (%s)", current.PostID, current.UpdatedAt.Format(time.RFC3339)),
+		})
+		for _, c := range merged.Conflicts {
+			conflicts = append(conflicts, fmt.Sprintf("body at line %d of the version you read:\n%s%s%s",
+				c.Line, indentLines("base:   ", c.Base), indentLines("yours:  ", c.Ours), indentLines("current:", c.Theirs)))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Conflict: post %d was updated at %s, after the version you read (%s), and your changes overlap with that update. Nothing was changed.\n",
+			current.PostID, current.UpdatedAt.Format(time.RFC3339), expected.Format(time.RFC3339))
+		for i, c := range conflicts {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, strings.TrimSuffix(c, "\n"))
+		}
+		if len(merged.Conflicts) > 0 {
+			fmt.Fprintf(&b, "Merged body with conflict markers:\n%s\n", strings.TrimSuffix(merged.Text, "\n"))
+		}
+		fmt.Fprintf(&b, "Resolve the conflicts (without the markers), then call update_post with the resolved content and expected_updated_at %s.",
+			current.UpdatedAt.Format(time.RFC3339))
+		return "", newToolResultError("%s", b.String())
+	}
+
+	if update.Body != "" {
+		update.Body = merged.Text
+	}
+	return fmt.Sprintf("Post %d was updated at %s, after the version you read (%s). Your changes were merged with that update.",
+		current.PostID, current.UpdatedAt.Format(time.RFC3339), expected.Format(time.RFC3339)), nil
+}
+
+// indentLines は衝突の報告のため、lines の各行に label を付けて字下げします
+func indentLines(label string, lines []string) string {
+	if len(lines) == 0 {
+		return "  " + label + " (nothing)\n"
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			label = strings.Repeat(" ", len(label))
+		}
+		fmt.Fprintf(&b, "  %s %s\n", label, line)
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+)
+
+func TestExpectedUpdatedAt(t *testing.T) {
+	var args updatePostArgs
+	err := bindArguments(map[string]interface{}{"post_id": float64(1), "expected_updated_at": "2024-01-02T15:04:05+09:00"}, &args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := time.Date(2024, 1, 2, 6, 4, 5, 0, time.UTC); args.ExpectedUpdatedAt == nil || !args.ExpectedUpdatedAt.Equal(want) {
+		t.Errorf("Expected %v, but got %v", want, args.ExpectedUpdatedAt)
+	}
+
+	err = bindArguments(map[string]interface{}{"post_id": float64(1), "expected_updated_at": "yesterday"}, &args)
+	if err == nil || !strings.Contains(err.Error(), "expected_updated_at") {
+		t.Errorf("Expected an error for expected_updated_at, but got %v", err)
+	}
+}
+
+func TestSameVersion(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	updatedAt := time.Date(2024, 1, 2, 15, 0, 0, 0, jst)
+	for _, c := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{updatedAt.UTC(), true},
+		{updatedAt.Add(250 * time.Millisecond), true},
+		{updatedAt.Add(time.Second), false},
+		{updatedAt.Add(-time.Millisecond), false},
+	} {
+		if got := sameVersion(updatedAt, c.t); got != c.want {
+			t.Errorf("sameVersion(%v, %v) = %v, but want %v", updatedAt, c.t, got, c.want)
+		}
+	}
+}
+
+func TestConflictResult(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	current := &docbase.GetPostResponse{
+		PostID:    1,
+		Body:      "a\nb edited by someone\nc\n",
+		UpdatedAt: time.Date(2024, 1, 2, 16, 0, 0, 0, jst),
+	}
+
+	result := conflictResult(current, time.Date(2024, 1, 2, 15, 0, 0, 0, jst), "a\nb\nc\nd\n")
+	if !result.IsError {
+		t.Fatal("Expected IsError to be set")
+	}
+	text := resultText(t, result)
+	for _, want := range []string{
+		"post 1 was updated at 2024-01-02T16:00:00+09:00, after the version you read (2024-01-02T15:00:00+09:00)",
+		"-b edited by someone\n+b\n",
+		"+d\n",
+		"expected_updated_at 2024-01-02T16:00:00+09:00",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the result:\n%s", want, text)
+		}
+	}
+}
+
+func TestMergeUpdate(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	expected := time.Date(2024, 1, 2, 15, 0, 0, 0, jst)
+	base := postVersion{Title: "手順", Body: "1. clone\n2. build\n3. test\n"}
+	current := &docbase.GetPostResponse{
+		PostID:    1,
+		Title:     "手順",
+		Body:      "1. clone\n2. build\n3. test\n4. deploy\n",
+		UpdatedAt: time.Date(2024, 1, 2, 16, 0, 0, 0, jst),
+	}
+
+	update := docbase.UpdatePostParam{Title: "手順", Body: "1. clone the repository\n2. build\n3. test\n"}
+	note, conflict := mergeUpdate(current, base, expected, &update)
+	if conflict != nil {
+		t.Fatalf("Unexpected conflict: %s", resultText(t, conflict))
+	}
+	if want := "1. clone the repository\n2. build\n3. test\n4. deploy\n"; update.Body != want {
+		t.Errorf("Expected the merged body %q, but got %q", want, update.Body)
+	}
+	if !strings.Contains(note, "merged") {
+		t.Errorf("Expected a note about the merge, but got %q", note)
+	}
+
+	update = docbase.UpdatePostParam{Body: "1. clone\n2. build\n3. test\n4. release\n"}
+	_, conflict = mergeUpdate(current, base, expected, &update)
+	if conflict == nil || !conflict.IsError {
+		t.Fatal("Expected a conflict")
+	}
+	text := resultText(t, conflict)
+	for _, want := range []string{
+		"1. body at line 4 of the version you read:",
+		"yours:   4. release",
+		"current: 4. deploy",
+		"<<<<<<< your body\n4. release\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the result:\n%s", want, text)
+		}
+	}
+}
@@ -3,10 +3,7 @@ package tools
 import (
 	"context"
 	"docbase-mcp-server/docbase"
-	"errors"
 	"fmt"
-	"os"
-	"strconv"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,11 +20,7 @@ func newCreateCommentTool() mcp.Tool {
 	return mcp.NewTool(
 		"create_comment",
 		mcp.WithDescription("Add a comment to a DocBase post"),
-		mcp.WithString(
-			"post_id",
-			mcp.Required(),
-			mcp.Description("The ID of the post to comment on"),
-		),
+		withPostID("The ID of the post to comment on"),
 		mcp.WithString(
 			"body",
 			mcp.Required(),
@@ -35,52 +28,86 @@ func newCreateCommentTool() mcp.Tool {
 		),
 		mcp.WithBoolean(
 			"notice",
-			mcp.Description("Whether to send notification or not (default is true)"),
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
 		),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
 	)
 }
 
-func handleCreateCommentRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client := docbase.NewDocBaseClient(
-		os.Getenv("DOCBASE_API_DOMAIN"),
-		os.Getenv("DOCBASE_API_TOKEN"),
-	)
+// createCommentArgs は create_comment の引数です
+type createCommentArgs struct {
+	PostID int64  `arg:"post_id,required" min:"1"`
+	Body   string `arg:"body,required"`
+	Notice *bool  `arg:"notice"`
+	DryRun bool   `arg:"dry_run"`
+}
 
-	// 投稿IDは必須
-	postIDStr, ok := request.Params.Arguments["post_id"].(string)
-	if !ok || postIDStr == "" {
-		return nil, errors.New("post_id is required")
+func handleCreateCommentRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
 	}
 
-	// 数値に変換
-	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	policy, err := teamPolicy(request)
 	if err != nil {
-		return nil, errors.New("post_id must be a valid number")
+		return newToolResultError("%v", err), nil
 	}
 
-	// コメント本文は必須
-	body, ok := request.Params.Arguments["body"].(string)
-	if !ok || body == "" {
-		return nil, errors.New("body is required")
+	var args createCommentArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
 	}
 
-	// 通知設定（デフォルトはtrue）
-	notice := true
-	if noticeParam, ok := request.Params.Arguments["notice"].(bool); ok {
-		notice = noticeParam
+	// コメントは投稿を読める人に公開されるため、投稿の公開範囲で検査する
+	post, err := client.GetPost(ctx, args.PostID)
+	if err != nil {
+		return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
+	}
+	notes, blocked := filterContent(post.Scope, contentField{Name: "body", Value: &args.Body})
+	if blocked != nil {
+		return blocked, nil
+	}
+
+	// 通知は省略された場合もポリシーの値を明示して送る
+	notice := policy.DefaultNotice
+	if args.Notice != nil {
+		notice = *args.Notice
 	}
+	reasons := visibilityChanges("", nil, "", nil, notice)
 
-	// コメント作成パラメータの設定
 	commentParam := docbase.CreateCommentParam{
-		Body:   body,
+		Body:   args.Body,
 		Notice: notice,
 	}
 
+	if isDryRun(args.DryRun) {
+		var groups []int
+		for _, g := range post.Groups {
+			groups = append(groups, g.ID)
+		}
+		return dryRun{
+			Method:  "POST",
+			URL:     fmt.Sprintf("%s/posts/%d/comments", client.BaseURL, args.PostID),
+			Payload: commentParam,
+			// コメントは投稿と同じ範囲に公開される
+			Details: scopeDetails(post.Scope, groups, notice),
+			Reasons: reasons,
+			Notes:   notes,
+		}.result(), nil
+	}
+
+	// 通知する場合は、ユーザーの確認を経てからコメントする
+	if result := requireConfirmation(request, reasons); result != nil {
+		return result, nil
+	}
+
 	// コメント作成APIの呼び出し
-	comment, err := client.CreateComment(ctx, postID, commentParam)
+	comment, err := client.CreateComment(ctx, args.PostID, commentParam)
 	if err != nil {
-		return nil, err
+		return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Comment created successfully!\nID: %d\nBody: %s", comment.ID, comment.Body)), nil
+	return mcp.NewToolResultText(withNotes(fmt.Sprintf("Comment created successfully!\nID: %d\nBody: %s", comment.ID, comment.Body), notes)), nil
 }
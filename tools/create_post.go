@@ -3,10 +3,8 @@ package tools
 import (
 	"context"
 	"docbase-mcp-server/docbase"
-	"errors"
 	"fmt"
-	"os"
-	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -39,88 +37,189 @@ func newCreatePostTool() mcp.Tool {
 		),
 		mcp.WithBoolean(
 			"notice",
-			mcp.Description("Whether to send notification or not (default is true)"),
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
 		),
-		mcp.WithString(
-			"tags",
-			mcp.Description("Comma-separated list of tags"),
-		),
-		mcp.WithString(
-			"scope",
-			mcp.Description("Scope of the post: 'everyone', 'group', or 'private' (default is 'everyone')"),
+		withTags(),
+		withScope(
+			mcp.Description("Who can read the post (default is the team's posting policy, 'private' unless configured)"),
 		),
+		withGroups(),
 		mcp.WithString(
-			"groups",
-			mcp.Description("Comma-separated list of group IDs (required if scope is 'group')"),
+			"idempotency_key",
+			mcp.Description("A unique key for this post, such as a UUID. If a post was already created with the same key, it is returned instead of creating another one. "+
+				"Use the same key when retrying after a timeout"),
 		),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
 	)
 }
 
+// createPostArgs は create_post の引数です
+type createPostArgs struct {
+	Title  string        `arg:"title,required"`
+	Body   string        `arg:"body,required"`
+	Draft  bool          `arg:"draft"`
+	Notice *bool         `arg:"notice"`
+	Tags   []string      `arg:"tags"`
+	Scope  docbase.Scope `arg:"scope" enum:"everyone,group,private"`
+	Groups []groupRef    `arg:"groups"`
+	DryRun bool          `arg:"dry_run"`
+
+	IdempotencyKey string `arg:"idempotency_key"`
+}
+
 func handleCreatePostRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client := docbase.NewDocBaseClient(
-		os.Getenv("DOCBASE_API_DOMAIN"),
-		os.Getenv("DOCBASE_API_TOKEN"),
-	)
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
 
-	title, ok := request.Params.Arguments["title"].(string)
-	if !ok || title == "" {
-		return nil, errors.New("title is required")
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
 	}
 
-	body, ok := request.Params.Arguments["body"].(string)
-	if !ok || body == "" {
-		return nil, errors.New("body is required")
+	var args createPostArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
 	}
 
-	draft, _ := request.Params.Arguments["draft"].(bool)
-	notice, _ := request.Params.Arguments["notice"].(bool)
+	// 重複の判定には、検査で書き換える前のタイトルと本文を使う
+	account, hash := accountID(client), contentHash(args.Title, args.Body)
 
-	var tags []string
-	if tagsStr, ok := request.Params.Arguments["tags"].(string); ok && tagsStr != "" {
-		tags = strings.Split(tagsStr, ",")
-		for i, tag := range tags {
-			tags[i] = strings.TrimSpace(tag)
-		}
+	// 省略された公開範囲と通知はチームのポリシーに従う
+	if args.Scope == "" {
+		args.Scope = policy.DefaultScope
 	}
-
-	scopeStr, _ := request.Params.Arguments["scope"].(string)
-	var scope docbase.Scope
-	switch scopeStr {
-	case "everyone":
-		scope = docbase.ScopeAll
-	case "group":
-		scope = docbase.ScopeGroup
-	case "private":
-		scope = docbase.ScopePrivate
-	default: // デフォルトはPrivateにする
-		scope = docbase.ScopePrivate
+	if err := policy.CheckScope(args.Scope); err != nil {
+		return newToolResultError("%v", err), nil
+	}
+	notice := policy.DefaultNotice
+	if args.Notice != nil {
+		notice = *args.Notice
 	}
 
+	// グループはスコープがgroupの場合だけ指定する
 	var groups []int
-	if groupsStr, ok := request.Params.Arguments["groups"].(string); ok && groupsStr != "" && scope == docbase.ScopeGroup {
-		groupStrs := strings.Split(groupsStr, ",")
-		for _, groupStr := range groupStrs {
-			var groupID int
-			if _, err := fmt.Sscanf(strings.TrimSpace(groupStr), "%d", &groupID); err == nil {
-				groups = append(groups, groupID)
-			}
+	if args.Scope == docbase.ScopeGroup {
+		if len(args.Groups) == 0 {
+			return newToolResultError("Invalid arguments: groups is required when scope is 'group'"), nil
+		}
+		groups, err = resolveGroups(ctx, client, args.Groups)
+		if err != nil {
+			return groupErrorResult(err), nil
 		}
 	}
 
+	notes, blocked := filterContent(args.Scope, contentField{Name: "title", Value: &args.Title}, contentField{Name: "body", Value: &args.Body})
+	if blocked != nil {
+		return blocked, nil
+	}
+
+	reasons := visibilityChanges(docbase.ScopePrivate, nil, args.Scope, groups, notice)
+
 	createParam := docbase.CreatePostParam{
-		Title:  title,
-		Body:   body,
-		Draft:  draft,
+		Title:  args.Title,
+		Body:   args.Body,
+		Draft:  args.Draft,
 		Notice: notice,
-		Tags:   tags,
-		Scope:  scope,
+		Tags:   args.Tags,
+		Scope:  args.Scope,
 		Groups: groups,
 	}
 
+	if isDryRun(args.DryRun) {
+		// ドライランでは重複を返さずに送るリクエストを示し、重複になる場合はそれを伝える
+		if existing, err := findCreatedPost(account, args.IdempotencyKey, hash, time.Now()); err == nil && existing != nil {
+			notes = append(notes, fmt.Sprintf("Without dry_run, no post would be created: post %d was already created by this server at %s with the same %s.",
+				existing.PostID, existing.CreatedAt.Format(time.RFC3339), duplicateBasis(args.IdempotencyKey)))
+		}
+		return dryRun{
+			Method:  "POST",
+			URL:     client.BaseURL + "/posts",
+			Payload: createParam,
+			Details: scopeDetails(args.Scope, groups, notice),
+			Reasons: reasons,
+			Notes:   notes,
+		}.result(), nil
+	}
+
+	// 再試行が最初の呼び出しの作成中に届いた場合も二重に作成しないよう、作成を記録し終えるまで予約する
+	release, ok := reserveCreation(account, args.IdempotencyKey, hash)
+	if !ok {
+		return newToolResultError("Another create_post call with the same %s is still in progress. "+
+			"Wait a moment and call again with the same arguments to get the post it created.", duplicateBasis(args.IdempotencyKey)), nil
+	}
+	defer release()
+
+	// 再試行で同じ投稿を二重に作成しないよう、このサーバーが作成した投稿を探す
+	existing, err := findCreatedPost(account, args.IdempotencyKey, hash, time.Now())
+	if err != nil {
+		return newToolResultError("Failed to read the record of created posts: %v", err), nil
+	}
+	if existing != nil {
+		if result := existingPostResult(ctx, client, existing, args.IdempotencyKey, hash); result != nil {
+			return result, nil
+		}
+	}
+
+	// 非公開以外で作成する場合と通知する場合は、ユーザーの確認を経てから作成する
+	if result := requireConfirmation(request, reasons); result != nil {
+		return result, nil
+	}
+
 	post, err := client.CreatePost(ctx, createParam)
 	if err != nil {
-		return nil, err
+		return apiErrorResult(err, "the new post"), nil
+	}
+	versions.remember(client, post)
+
+	if err := recordCreatedPost(createdPost{
+		Account:   account,
+		Key:       args.IdempotencyKey,
+		Hash:      hash,
+		PostID:    post.PostID,
+		CreatedAt: time.Now(),
+		Notice:    notice,
+	}); err != nil {
+		notes = append(notes, fmt.Sprintf("Warning: failed to record the post for deduplication: %v", err))
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Post created successfully!\nTitle: %s\nID: %d", post.Title, post.PostID)), nil
+	return mcp.NewToolResultText(withNotes(formatPostResult("Post created successfully!", post, notice), notes)), nil
+}
+
+// existingPostResult は作成済みの投稿を、新しく作成する代わりの結果として返します
+// idempotency_key のない重複の判定で元の投稿を読めない場合は nil を返し、作成を続けさせます
+func existingPostResult(ctx context.Context, client *docbase.DocBaseClient, existing *createdPost, key, hash string) *mcp.CallToolResult {
+	if key != "" && existing.Hash != hash {
+		return newToolResultError("idempotency_key %q was already used for post %d with a different title or body. "+
+			"Use a new key to create another post.", key, existing.PostID)
+	}
+
+	post, err := client.GetPost(ctx, existing.PostID)
+	if err != nil {
+		if key == "" {
+			return nil
+		}
+		return apiErrorResult(err, fmt.Sprintf("post %d", existing.PostID))
+	}
+
+	var message string
+	if key != "" {
+		message = fmt.Sprintf("Post already created with idempotency_key %q at %s. No new post was created.",
+			key, existing.CreatedAt.Format(time.RFC3339))
+	} else {
+		message = fmt.Sprintf("A post with the same title and body was created at %s. No new post was created. "+
+			"To create another one anyway, call again with a new idempotency_key.", existing.CreatedAt.Format(time.RFC3339))
+	}
+	return mcp.NewToolResultText(formatPostResult(message, post, existing.Notice))
+}
+
+// duplicateBasis は重複と判定する根拠を説明します
+func duplicateBasis(key string) string {
+	if key != "" {
+		return fmt.Sprintf("idempotency_key %q", key)
+	}
+	return "title and body"
 }
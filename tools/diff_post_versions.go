@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"docbase-mcp-server/textdiff"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewDiffPostVersionsTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newDiffPostVersionsTool(),
+		Handler: handleDiffPostVersionsRequest,
+	}
+}
+
+func newDiffPostVersionsTool() mcp.Tool {
+	return mcp.NewTool(
+		"diff_post_versions",
+		mcp.WithDescription("Show the differences between two versions of a DocBase post in the local history (see list_post_history), "+
+			"or between a version and the current post"),
+		withPostID("The ID of the post"),
+		withInteger(
+			"from",
+			mcp.Required(),
+			mcp.Description("The version to compare from"),
+			mcp.Min(1),
+		),
+		withInteger(
+			"to",
+			mcp.Description("The version to compare to (default is the current post in DocBase)"),
+			mcp.Min(1),
+		),
+		withTeam(),
+	)
+}
+
+// diffPostVersionsArgs は diff_post_versions の引数です
+type diffPostVersionsArgs struct {
+	PostID int64 `arg:"post_id,required" min:"1"`
+	From   int   `arg:"from,required" min:"1"`
+	To     *int  `arg:"to" min:"1"`
+}
+
+func handleDiffPostVersionsRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args diffPostVersionsArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+
+	from, err := findHistoryVersion(client, args.PostID, args.From)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var toName, toTitle, toBody string
+	if args.To != nil {
+		to, err := findHistoryVersion(client, args.PostID, *args.To)
+		if err != nil {
+			return newToolResultError("%v", err), nil
+		}
+		toName, toTitle, toBody = fmt.Sprintf("version %d", to.Version), to.Title, to.Body
+	} else {
+		current, err := client.GetPost(ctx, args.PostID)
+		if err != nil {
+			return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
+		}
+		versions.remember(client, current)
+		toName, toTitle, toBody = "current", current.Title, current.Body
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Post %d, version %d -> %s", args.PostID, from.Version, toName)
+	if from.Title != toTitle {
+		fmt.Fprintf(&b, "\nTitle: %q -> %q", from.Title, toTitle)
+	}
+	diff := textdiff.Unified(from.Body, toBody, fmt.Sprintf("version %d", from.Version), toName, 3)
+	if diff == "" {
+		b.WriteString("\nThe bodies are the same.")
+	} else {
+		b.WriteString("\n" + strings.TrimSuffix(diff, "\n"))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// dryRunAll は設定で書き込み系のツールをすべてドライランにしているかどうかです
+var dryRunAll atomic.Bool
+
+// SetDryRun は書き込み系のツールを常にドライランで実行するかどうかを登録します
+// 起動時に一度呼び出します
+func SetDryRun(enabled bool) {
+	dryRunAll.Store(enabled)
+}
+
+// isDryRun は dry_run 引数か設定のどちらかでドライランが指定されているかを返します
+func isDryRun(arg bool) bool {
+	return arg || dryRunAll.Load()
+}
+
+// withDryRun は書き込み系のツールに共通の dry_run 引数を定義します
+func withDryRun() mcp.ToolOption {
+	return mcp.WithBoolean(
+		"dry_run",
+		mcp.Description("If true, nothing is written to DocBase. The result shows the request that would be sent, "+
+			"the effective scope and groups, and for updates a diff of the body against the current post (default is false)"),
+	)
+}
+
+// dryRun はドライランで送らなかったリクエストの内容です
+type dryRun struct {
+	Method string
+	URL    string
+	// Payload はリクエストの本文として送る値です
+	Payload interface{}
+	// Details は実際に適用される公開範囲やグループなど、ペイロードだけではわからない情報です
+	Details []string
+	// Diff は更新前後の本文の差分です。更新でない場合や本文が変わらない場合は空です
+	Diff string
+	// Reasons は実際に実行した場合に確認が必要になる理由です
+	Reasons []string
+	// Notes は filterContent の報告です
+	Notes []string
+}
+
+// result は dryRun の内容をツールの結果にします
+func (d dryRun) result() *mcp.CallToolResult {
+	payload, err := json.MarshalIndent(d.Payload, "", "  ")
+	if err != nil {
+		return newToolResultError("Failed to encode the request payload: %v", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("Dry run: nothing was sent to DocBase.\n")
+	fmt.Fprintf(&b, "Request: %s %s\nPayload:\n%s", d.Method, d.URL, payload)
+	for _, detail := range d.Details {
+		b.WriteString("\n" + detail)
+	}
+	if d.Diff != "" {
+		b.WriteString("\nBody diff:\n" + strings.TrimSuffix(d.Diff, "\n"))
+	}
+	if len(d.Reasons) > 0 {
+		fmt.Fprintf(&b, "\nWithout dry_run, this call would ask for confirmation because it would:\n- %s", strings.Join(d.Reasons, "\n- "))
+	}
+	return mcp.NewToolResultText(withNotes(b.String(), d.Notes))
+}
+
+// scopeDetails は実際に適用される公開範囲、グループ、通知の有無を dryRun.Details の形式で返します
+func scopeDetails(scope docbase.Scope, groups []int, notice bool) []string {
+	details := []string{fmt.Sprintf("Scope: %s", scope)}
+	if scope == docbase.ScopeGroup {
+		ids := make([]string, 0, len(groups))
+		for _, id := range groups {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		details = append(details, fmt.Sprintf("Groups: %s", strings.Join(ids, ", ")))
+	}
+	return append(details, fmt.Sprintf("Notice: %t", notice))
+}
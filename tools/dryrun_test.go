@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCreatePostDryRun(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+
+	// 公開範囲が private でグループの解決も不要なため、DocBaseにはリクエストを送らない
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "create_post"
+	request.Params.Arguments = map[string]interface{}{
+		"title":   "手順",
+		"body":    "連絡先: taro@example.com",
+		"notice":  true,
+		"dry_run": true,
+	}
+
+	result, err := handleCreatePostRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %s", text)
+	}
+	for _, want := range []string{
+		"Dry run: nothing was sent to DocBase.",
+		"Request: POST https://api.docbase.io/teams/example/posts",
+		`"title": "手順"`,
+		`"scope": "private"`,
+		"Notice: true",
+		"send a notification",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the result:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "confirmation_token") {
+		t.Errorf("Expected no confirmation token for a dry run:\n%s", text)
+	}
+}
+
+func TestDryRunResult(t *testing.T) {
+	result := dryRun{
+		Method:  "PATCH",
+		URL:     "https://api.docbase.io/teams/example/posts/1",
+		Payload: docbase.UpdatePostParam{Body: "new\n"},
+		Details: scopeDetails(docbase.ScopeGroup, []int{3, 5}, false),
+		Diff:    "--- post 1 (current)\n+++ post 1 (updated)\n@@ -1 +1 @@\n-old\n+new\n",
+	}.result()
+
+	want := "Dry run: nothing was sent to DocBase.\n" +
+		"Request: PATCH https://api.docbase.io/teams/example/posts/1\n" +
+		"Payload:\n{\n  \"body\": \"new\\n\"\n}\n" +
+		"Scope: group\nGroups: 3, 5\nNotice: false\n" +
+		"Body diff:\n--- post 1 (current)\n+++ post 1 (updated)\n@@ -1 +1 @@\n-old\n+new"
+	if text := resultText(t, result); text != want {
+		t.Errorf("Unexpected result:\n%s", text)
+	}
+}
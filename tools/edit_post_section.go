@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+
+	"docbase-mcp-server/markdown"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewEditPostSectionTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newEditPostSectionTool(),
+		Handler: handleEditPostSectionRequest,
+	}
+}
+
+func newEditPostSectionTool() mcp.Tool {
+	return mcp.NewTool(
+		"edit_post_section",
+		mcp.WithDescription("Edit one markdown section of a DocBase post, found by its heading, without resending the whole body. "+
+			"The rest of the body is kept byte for byte"),
+		withPostID("The ID of the post to edit"),
+		mcp.WithString(
+			"section",
+			mcp.Required(),
+			mcp.Description("The heading of the section, e.g. 'Action Items'. "+
+				"If the heading appears more than once, give its parent headings separated by '>', e.g. 'Release plan > Rollback'"),
+		),
+		mcp.WithString(
+			"operation",
+			mcp.Required(),
+			mcp.Description("replace: replace everything under the heading (including subsections), keeping the heading. "+
+				"append: add to the end of the section's own text, before its first subsection. "+
+				"prepend: add right after the heading. "+
+				"delete: remove the heading and everything under it"),
+			mcp.Enum("replace", "append", "prepend", "delete"),
+		),
+		mcp.WithString(
+			"content",
+			mcp.Description("The markdown to insert or to replace the section with (required unless operation is delete)"),
+		),
+		mcp.WithBoolean(
+			"notice",
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
+		),
+		withExpectedUpdatedAt(),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
+	)
+}
+
+// editPostSectionArgs は edit_post_section の引数です
+type editPostSectionArgs struct {
+	PostID    int64              `arg:"post_id,required" min:"1"`
+	Section   string             `arg:"section,required"`
+	Operation markdown.Operation `arg:"operation,required" enum:"replace,append,prepend,delete"`
+	Content   string             `arg:"content"`
+	Notice    *bool              `arg:"notice"`
+	DryRun    bool               `arg:"dry_run"`
+
+	ExpectedUpdatedAt *timestamp `arg:"expected_updated_at"`
+}
+
+func handleEditPostSectionRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args editPostSectionArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+	if args.Content == "" && args.Operation != markdown.Delete {
+		return newToolResultError("Invalid arguments: content is required when operation is '%s'", args.Operation), nil
+	}
+	path := markdown.ParsePath(args.Section)
+	if len(path) == 0 {
+		return newToolResultError("Invalid arguments: section must be a heading such as 'Action Items'"), nil
+	}
+
+	result, _ := updatePost(ctx, request, client, policy, updatePostArgs{
+		PostID:            args.PostID,
+		Notice:            args.Notice,
+		DryRun:            args.DryRun,
+		ExpectedUpdatedAt: args.ExpectedUpdatedAt,
+		edit: func(body string) (string, error) {
+			return markdown.Edit(body, path, args.Operation, args.Content)
+		},
+	}, "Post section edited successfully!")
+	return result, nil
+}
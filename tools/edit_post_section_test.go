@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEditPostSection(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+	updatedAt := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	body := "# 議事録\n\n## 決定事項\n\n- リリースは来週\n\n## Action Items\n\n- [ ] 手順書\n\n## メモ\n\n特になし\n"
+	fake := newFakeDocBase(t, docbase.GetPostResponse{
+		PostID:    1,
+		Title:     "定例",
+		Body:      body,
+		Scope:     docbase.ScopePrivate,
+		UpdatedAt: updatedAt,
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "edit_post_section"
+	request.Params.Arguments = map[string]interface{}{
+		"post_id":   float64(1),
+		"section":   "Action Items",
+		"operation": "append",
+		"content":   "- [ ] リリースノート",
+	}
+	result, err := handleEditPostSectionRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := resultText(t, result); result.IsError || !strings.Contains(text, "Post section edited successfully!") {
+		t.Fatalf("Expected the section to be edited, but got %q", text)
+	}
+
+	want := strings.Replace(body, "- [ ] 手順書\n", "- [ ] 手順書\n- [ ] リリースノート\n", 1)
+	if got := fake.post(1).Body; got != want {
+		t.Errorf("Expected only the section to change:\n%s\nbut got:\n%s", want, got)
+	}
+	// タイトルは変えないため送らない
+	if len(fake.updates) != 1 || fake.updates[0].Title != "" {
+		t.Errorf("Expected one update without the title, but got %+v", fake.updates)
+	}
+
+	// 見出しがなければ何も送らない
+	request.Params.Arguments["section"] = "Missing"
+	result, err = handleEditPostSectionRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), "Nothing was changed") {
+		t.Errorf("Expected an error for a missing section, but got %q", resultText(t, result))
+	}
+	if len(fake.updates) != 1 {
+		t.Errorf("Expected no more updates, but got %d", len(fake.updates))
+	}
+}
+
+func TestEditPostSectionFiltersOnlyTheEdit(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+	// 全体に公開した投稿では、既定でメールアドレスを伏せ字にする
+	body := "## 連絡先\n\ntaro@example.com\n\n## Action Items\n\n- [ ] 手順書\n"
+	fake := newFakeDocBase(t, docbase.GetPostResponse{
+		PostID:    1,
+		Title:     "定例",
+		Body:      body,
+		Scope:     docbase.ScopeAll,
+		UpdatedAt: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC),
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "edit_post_section"
+	request.Params.Arguments = map[string]interface{}{
+		"post_id":   float64(1),
+		"section":   "Action Items",
+		"operation": "append",
+		"content":   "- [ ] hanako@example.com に連絡",
+	}
+	result, err := handleEditPostSectionRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if result.IsError {
+		t.Fatalf("Expected the section to be edited, but got %q", text)
+	}
+
+	want := "## 連絡先\n\ntaro@example.com\n\n## Action Items\n\n- [ ] 手順書\n- [ ] [REDACTED:email] に連絡\n"
+	if got := fake.post(1).Body; got != want {
+		t.Errorf("Expected only the new address to be redacted:\n%s\nbut got:\n%s", want, got)
+	}
+	if !strings.Contains(text, "body line 8, column 7: email") || strings.Contains(text, "line 3") {
+		t.Errorf("Expected only the new address to be reported, but got %q", text)
+	}
+}
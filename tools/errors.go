@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newToolResultError は IsError を設定したツールの結果を作成します
+// 引数の誤りやAPIのエラーは、JSON-RPCのエラーではなくこの結果で返し、モデルが引数を直して再試行できるようにします
+func newToolResultError(format string, args ...any) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewTextContent(fmt.Sprintf(format, args...))},
+		IsError: true,
+	}
+}
+
+// apiErrorResult はDocBase APIの呼び出しで発生したエラーを、対処方法がわかるメッセージのツールの結果にします
+// target はエラーの対象を表す "post 123" のような文字列です
+func apiErrorResult(err error, target string) *mcp.CallToolResult {
+	if errors.Is(err, docbase.ErrReadOnly) {
+		return newToolResultError("%v. Only reading tools can be used.", err)
+	}
+
+	var apiErr *docbase.APIError
+	if !errors.As(err, &apiErr) {
+		return newToolResultError("Failed to call the DocBase API: %v", err)
+	}
+
+	switch apiErr.StatusCode {
+	case 400, 422:
+		return newToolResultError("DocBase rejected the request for %s: %v. Fix the arguments and try again.", target, apiErr)
+	case 401:
+		return newToolResultError("The DocBase API token is invalid or expired (%v). Ask the user to check the token configured for this team.", apiErr)
+	case 403:
+		return newToolResultError("This token is not allowed to access %s (%v).", target, apiErr)
+	case 404:
+		return newToolResultError("%s not found or not visible to this token. Check the ID, or search for the post with search_posts.", target)
+	case 429:
+		return newToolResultError("The DocBase API rate limit was exceeded. Wait until the limit resets before trying again.")
+	default:
+		return newToolResultError("DocBase failed to handle the request for %s: %v. Try again later.", target, apiErr)
+	}
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) != 1 {
+		t.Fatalf("Expected one content, but got %d", len(result.Content))
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected text content, but got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+// setTestTeams は policy を持つ example チームだけを設定し、テストの終わりに設定を戻します
+func setTestTeams(t *testing.T, policy docbase.Policy) {
+	t.Helper()
+	teams, err := docbase.NewTeams([]docbase.Team{{Name: "example", Domain: "example", APIToken: "token", Policy: policy}}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	docbase.SetTeams(teams)
+	t.Cleanup(func() { docbase.SetTeams(nil) })
+}
+
+func TestAPIErrorResult(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&docbase.APIError{StatusCode: 404}, "post 123 not found or not visible to this token"},
+		{&docbase.APIError{StatusCode: 400, Messages: []string{"title is too long"}}, "title is too long"},
+		{fmt.Errorf("wrapped: %w", docbase.ErrReadOnly), "read-only mode"},
+	}
+
+	for _, tt := range tests {
+		result := apiErrorResult(tt.err, "post 123")
+		if !result.IsError {
+			t.Errorf("Expected IsError to be set for %v", tt.err)
+		}
+		if text := resultText(t, result); !strings.Contains(text, tt.want) {
+			t.Errorf("Expected %q to contain %q", text, tt.want)
+		}
+	}
+}
+
+func TestValidationErrorsAreToolErrors(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"title": "title", "body": "body", "scope": "public"}
+
+	result, err := handleCreatePostRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected a tool error instead of a protocol error, but got %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected IsError to be set")
+	}
+	if text := resultText(t, result); !strings.Contains(text, "'everyone', 'group', 'private'") {
+		t.Errorf("Expected the valid scopes in %q", text)
+	}
+}
+
+func TestPostingPolicy(t *testing.T) {
+	setTestTeams(t, docbase.Policy{
+		DefaultScope:  docbase.ScopePrivate,
+		AllowedScopes: []docbase.Scope{docbase.ScopePrivate, docbase.ScopeGroup},
+	})
+
+	for _, handler := range []func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		handleCreatePostRequest,
+		handleUpdatePostRequest,
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"post_id": float64(1), "title": "title", "body": "body", "scope": "everyone"}
+
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if text := resultText(t, result); !result.IsError || !strings.Contains(text, "allowed scopes: 'private', 'group'") {
+			t.Errorf("Expected the policy to reject the scope, but got %q", text)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/markdown"
+	"docbase-mcp-server/textdiff"
+)
+
+// outlineExcerpt は本文の見出しを行番号とセクションの行の範囲つきで返します
+func outlineExcerpt(body string) string {
+	total := len(textdiff.SplitLines(body))
+	sections := markdown.Sections(body)
+	if len(sections) == 0 {
+		return fmt.Sprintf("Outline: the body has no headings (%d lines). Read it in parts with start_line and end_line.", total)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Outline (%d lines):", total)
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n%s%s %s (lines %d-%d)", strings.Repeat("  ", s.Level-1), strings.Repeat("#", s.Level), s.Title, s.Line, lastLine(body, s.Start, s.End))
+	}
+	b.WriteString("\nRead one section with section (e.g. \"Parent > Heading\"), or a line range with start_line and end_line.")
+	return b.String()
+}
+
+// sectionExcerpt は path の見出しのセクションを、前後の見出しの案内つきで返します
+// maxChars 文字より長いセクションは分割して、続きをカーソルで読ませます
+func sectionExcerpt(post *docbase.GetPostResponse, path []string, maxChars int) (string, error) {
+	body := post.Body
+	s, err := markdown.Find(body, path)
+	if err != nil {
+		return "", err
+	}
+
+	var before, after string
+	for _, other := range markdown.Sections(body) {
+		if other.Start < s.Start {
+			before = fmt.Sprintf("%s %s (line %d)", strings.Repeat("#", other.Level), other.Title, other.Line)
+		}
+		if other.Start >= s.End && after == "" {
+			after = fmt.Sprintf("%s %s (line %d)", strings.Repeat("#", other.Level), other.Title, other.Line)
+		}
+	}
+	if before == "" {
+		before = fmt.Sprintf("lines 1-%d", s.Line-1)
+		if s.Line == 1 {
+			before = "start of the post"
+		}
+	}
+	if after == "" {
+		after = "end of the post"
+	}
+
+	text, err := chunkedBody(post, nil, s.Start, s.End, maxChars, "Body:\n%s")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Section: %s (lines %d-%d of %d)\nBefore: %s\nAfter: %s\n%s",
+		strings.Join(markdown.PathAt(body, s.Start), " > "), s.Line, lastLine(body, s.Start, s.End), len(textdiff.SplitLines(body)),
+		before, after, text), nil
+}
+
+// lineExcerpt は本文の start 行目から end 行目までを、前後の範囲の案内つきで返します
+// end が0または最後の行より後の場合は最後の行までを返します
+func lineExcerpt(post *docbase.GetPostResponse, start, end, maxChars int) (string, error) {
+	body := post.Body
+	lines := textdiff.SplitLines(body)
+	total := len(lines)
+	if end == 0 || end > total {
+		end = total
+	}
+	if start > total {
+		return "", fmt.Errorf("start_line %d is past the end of the post (%d lines)", start, total)
+	}
+	if start > end {
+		return "", fmt.Errorf("start_line %d is after end_line %d", start, end)
+	}
+
+	offset := 0
+	for _, line := range lines[:start-1] {
+		offset += len(line) + 1
+	}
+	// 最後の行に改行がない場合もあるため、範囲の終わりは本文の長さまでにする
+	endOffset := offset
+	for _, line := range lines[start-1 : end] {
+		endOffset += len(line) + 1
+	}
+	return formatExcerpt(fmt.Sprintf("Lines %d-%d of %d", start, end, total), post, offset, min(endOffset, len(body)), maxChars,
+		rangeHint(1, start-1, "lines", fmt.Sprintf("end_line %d", start-1), "start of the post"),
+		rangeHint(end+1, total, "lines", fmt.Sprintf("start_line %d", end+1), "end of the post"))
+}
+
+// charExcerpt は本文の offset 文字目から count 文字を、前後の範囲の案内つきで返します
+// count が0の場合は最後までを返します
+func charExcerpt(post *docbase.GetPostResponse, offset, count, maxChars int) (string, error) {
+	body := post.Body
+	runes := []rune(body)
+	total := len(runes)
+	if offset >= total {
+		return "", fmt.Errorf("char_offset %d is past the end of the post (%d characters)", offset, total)
+	}
+	end := total
+	if count > 0 && offset+count < total {
+		end = offset + count
+	}
+
+	byteOffset := len(string(runes[:offset]))
+	text := string(runes[offset:end])
+	title := fmt.Sprintf("Characters %d-%d of %d (lines %d-%d)", offset, end-1, total,
+		markdown.LineAt(body, byteOffset), lastLine(body, byteOffset, byteOffset+len(text)))
+	return formatExcerpt(title, post, byteOffset, byteOffset+len(text), maxChars,
+		rangeHint(0, offset-1, "characters", fmt.Sprintf("char_offset 0 and char_count %d", offset), "start of the post"),
+		rangeHint(end, total-1, "characters", fmt.Sprintf("char_offset %d", end), "end of the post"))
+}
+
+// rangeHint は読んでいない範囲 [from, to] と、それを読むための引数 call を案内します
+// 範囲が空の場合は none を返します
+func rangeHint(from, to int, unit, call, none string) string {
+	if from > to {
+		return none
+	}
+	return fmt.Sprintf("%s %d-%d (call with %s)", unit, from, to, call)
+}
+
+// formatExcerpt は本文の [start, end) の範囲に、それを含むセクションと前後の案内を付けます
+// maxChars 文字より長い範囲は分割して、続きをカーソルで読ませます
+func formatExcerpt(title string, post *docbase.GetPostResponse, start, end, maxChars int, before, after string) (string, error) {
+	text, err := chunkedBody(post, nil, start, end, maxChars, "Body:\n%s")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(title)
+	if path := markdown.PathAt(post.Body, start); len(path) > 0 {
+		fmt.Fprintf(&b, ", in section %s", strings.Join(path, " > "))
+	}
+	fmt.Fprintf(&b, "\nBefore: %s\nAfter: %s\n%s", before, after, text)
+	return b.String(), nil
+}
+
+// lastLine は本文の [start, end) の範囲の最後の行番号を返します
+func lastLine(body string, start, end int) int {
+	if end <= start {
+		return markdown.LineAt(body, start)
+	}
+	// 末尾の改行は次の行の始まりではない
+	_, size := utf8.DecodeLastRuneInString(body[:end])
+	if body[end-size:end] == "\n" {
+		end -= size
+	}
+	return markdown.LineAt(body, max(end, start))
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/markdown"
+)
+
+const designDoc = `# 設計
+概要
+
+## 構成
+- API
+- DB
+
+## 移行
+### 手順
+1. backup
+2. migrate
+### Rollback
+1. restore
+`
+
+func TestExcerpts(t *testing.T) {
+	post := &docbase.GetPostResponse{PostID: 1, Body: designDoc}
+	section, err := sectionExcerpt(post, markdown.ParsePath("移行 > Rollback"), DefaultMaxBodyChars)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines, err := lineExcerpt(post, 5, 6, DefaultMaxBodyChars)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	chars, err := charExcerpt(post, 0, 7, DefaultMaxBodyChars)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "outline",
+			got:  outlineExcerpt(designDoc),
+			want: "Outline (13 lines):\n" +
+				"# 設計 (lines 1-13)\n" +
+				"  ## 構成 (lines 4-7)\n" +
+				"  ## 移行 (lines 8-13)\n" +
+				"    ### 手順 (lines 9-11)\n" +
+				"    ### Rollback (lines 12-13)\n" +
+				"Read one section with section (e.g. \"Parent > Heading\"), or a line range with start_line and end_line.",
+		},
+		{
+			name: "section",
+			got:  section,
+			want: "Section: 設計 > 移行 > Rollback (lines 12-13 of 13)\n" +
+				"Before: ### 手順 (line 9)\nAfter: end of the post\nBody:\n### Rollback\n1. restore\n",
+		},
+		{
+			name: "lines",
+			got:  lines,
+			want: "Lines 5-6 of 13, in section 設計 > 構成\n" +
+				"Before: lines 1-4 (call with end_line 4)\nAfter: lines 7-13 (call with start_line 7)\nBody:\n- API\n- DB\n",
+		},
+		{
+			name: "characters",
+			got:  chars,
+			want: "Characters 0-6 of 85 (lines 1-2), in section 設計\n" +
+				"Before: start of the post\nAfter: characters 7-84 (call with char_offset 7)\nBody:\n# 設計\n概要",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Unexpected excerpt:\n%s", tt.got)
+			}
+		})
+	}
+
+	if _, err := lineExcerpt(post, 20, 0, DefaultMaxBodyChars); err == nil {
+		t.Error("Expected an error for a start_line past the end")
+	}
+}
+
+// splitPart は chunkedBody が返した部分の本文と meta を返します
+func splitPart(t *testing.T, text string) (string, chunkMeta) {
+	t.Helper()
+	body := text[strings.Index(text, "):\n")+3:]
+	i := strings.LastIndex(body, "Meta: ")
+	if i < 0 {
+		t.Fatalf("Expected the meta of the part, but got:\n%s", text)
+	}
+	var meta chunkMeta
+	line, _, _ := strings.Cut(body[i+len("Meta: "):], "\n")
+	if err := json.Unmarshal([]byte(line), &meta); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return body[:i], meta
+}
+
+func TestChunkedBody(t *testing.T) {
+	post := &docbase.GetPostResponse{
+		PostID:    1,
+		Body:      "# 設計\n" + strings.Repeat("本文の段落です。\n\n", 60) + "## 移行\n" + strings.Repeat("手順の段落です。\n\n", 200),
+		UpdatedAt: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC),
+	}
+
+	var parts []string
+	var cursor *bodyCursor
+	for i := 0; i < 10; i++ {
+		text, err := chunkedBody(post, cursor, 0, len(post.Body), 1000, "Body: %s\n")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		part, meta := splitPart(t, text)
+		parts = append(parts, part)
+		if meta.Page != i+1 || meta.Total < 2 || (meta.PreviousPage == nil) != (i == 0) {
+			t.Errorf("Unexpected meta of part %d: %+v", i+1, meta)
+		}
+		if meta.NextCursor == "" {
+			break
+		}
+
+		var c bodyCursor
+		if err := c.decodeArgument(meta.NextCursor); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cursor = &c
+	}
+
+	if got := strings.Join(parts, ""); got != post.Body {
+		t.Errorf("Expected the parts to make up the body, but got %d parts of %d bytes", len(parts), len(got))
+	}
+	if len(parts) < 2 || !strings.HasPrefix(parts[1], "## 移行\n") {
+		t.Errorf("Expected the second part to start at the heading, but got %q", parts[1][:20])
+	}
+
+	// 投稿が更新された後のカーソルは使えない
+	post.UpdatedAt = post.UpdatedAt.Add(time.Minute)
+	if _, err := chunkedBody(post, cursor, 0, 0, 1000, ""); err == nil || !strings.Contains(err.Error(), "after this cursor was issued") {
+		t.Errorf("Expected an error for a stale cursor, but got %v", err)
+	}
+}
+
+func TestLongSectionInParts(t *testing.T) {
+	post := &docbase.GetPostResponse{
+		PostID:    1,
+		Body:      "# 設計\n概要\n## 移行\n" + strings.Repeat("手順の段落です。\n\n", 300) + "## 運用\n監視\n",
+		UpdatedAt: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC),
+	}
+	section, err := markdown.Find(post.Body, []string{"移行"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	text, err := sectionExcerpt(post, []string{"移行"}, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Body (part 1 of ") || !strings.Contains(text, `"next_cursor":`) {
+		t.Fatalf("Expected the section to be returned in parts, but got:\n%s", text)
+	}
+
+	// カーソルの続きはセクションの中だけを読む
+	var parts []string
+	for {
+		part, meta := splitPart(t, text)
+		parts = append(parts, part)
+		if meta.NextCursor == "" {
+			break
+		}
+		var cursor bodyCursor
+		if err := cursor.decodeArgument(meta.NextCursor); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if text, err = chunkedBody(post, &cursor, 0, 0, 1000, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if got := strings.Join(parts, ""); got != post.Body[section.Start:section.End] {
+		t.Errorf("Expected the parts to make up the section, but got %d parts of %d bytes", len(parts), len(got))
+	}
+
+	// 上限より大きい文字数を書き込んだカーソルでも、上限を超えて読ませない
+	honest := bodyCursor{updatedAt: post.UpdatedAt.Unix(), start: 0, end: len(post.Body), maxChars: 1000}
+	forged := honest
+	forged.maxChars = 1 << 30
+	want, err := chunkedBody(post, &honest, 0, 0, 1000, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := chunkedBody(post, &forged, 0, 0, 1000, ""); got != want {
+		t.Errorf("Expected the forged cursor to be limited to 1000 characters, but got:\n%s", got[:min(len(got), 200)])
+	}
+
+	// 文字の途中を指すカーソルは使えない
+	broken := bodyCursor{updatedAt: post.UpdatedAt.Unix(), start: 3, end: len(post.Body), maxChars: 1000}
+	if _, err := chunkedBody(post, &broken, 0, 0, 1000, ""); err == nil {
+		t.Error("Expected an error for a cursor in the middle of a character")
+	}
+}
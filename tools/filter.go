@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/scan"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SecretPolicy は投稿やコメントに含まれる認証情報の扱いを表します
+type SecretPolicy struct {
+	Action  scan.Action
+	Scanner *scan.Scanner
+}
+
+// PIIPolicy は投稿やコメントに含まれる個人情報の、公開範囲ごとの扱いを表します
+type PIIPolicy struct {
+	// Actions は公開範囲ごとの扱いです。含まれない公開範囲では検査しません
+	Actions map[docbase.Scope]scan.Action
+	Scanner *scan.Scanner
+}
+
+var (
+	filterMu     sync.RWMutex
+	secretPolicy = SecretPolicy{
+		Action:  scan.ActionBlock,
+		Scanner: scan.NewSecretScanner(nil, true),
+	}
+	piiPolicy = PIIPolicy{
+		Actions: map[docbase.Scope]scan.Action{docbase.ScopeAll: scan.ActionRedact},
+		Scanner: scan.NewPIIScanner(nil),
+	}
+)
+
+// SetSecretPolicy は書き込み系のツールが使う認証情報の検査の設定を登録します
+// 起動時に一度呼び出します
+func SetSecretPolicy(p SecretPolicy) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	secretPolicy = p
+}
+
+// SetPIIPolicy は書き込み系のツールが使う個人情報の検査の設定を登録します
+// 起動時に一度呼び出します
+func SetPIIPolicy(p PIIPolicy) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	piiPolicy = p
+}
+
+// contentField はDocBaseに送る文字列の引数です
+// 検査で伏せ字にした場合は Value を書き換えます
+type contentField struct {
+	Name  string
+	Value *string
+	// Known は同じ公開範囲で既にDocBaseにある内容です。ここに含まれる文字列は検出しません
+	Known string
+}
+
+// filterStage は検査の1段階です
+type filterStage struct {
+	action  scan.Action
+	scanner *scan.Scanner
+	// what は報告に使う検出対象の説明です
+	what string
+	// hint はブロックしたときに伝える対処方法です
+	hint string
+}
+
+// filterContent はDocBaseに送る文字列を、認証情報、個人情報の順に検査します
+// scope は送った内容を読める範囲で、個人情報の扱いを決めるのに使います
+// 送信を中止する場合はツールの結果を、送信する場合は結果に添える報告を返します
+func filterContent(scope docbase.Scope, fields ...contentField) (notes []string, blocked *mcp.CallToolResult) {
+	filterMu.RLock()
+	stages := []filterStage{
+		{
+			action:  secretPolicy.Action,
+			scanner: secretPolicy.Scanner,
+			what:    "secrets or credentials",
+			hint: "Remove or mask them (e.g. replace the value with ****) and try again. " +
+				"If they are not real secrets, ask the user before rewording them.",
+		},
+		{
+			action:  piiPolicy.Actions[scope],
+			scanner: piiPolicy.Scanner,
+			what:    fmt.Sprintf("personal information that must not be posted with scope '%s'", scope),
+			hint:    "Remove the personal information, or post with a narrower scope if the team's policy allows it.",
+		},
+	}
+	filterMu.RUnlock()
+
+	for _, stage := range stages {
+		if stage.action == "" || stage.action == scan.ActionAllow {
+			continue
+		}
+
+		var findings []scan.Finding
+		for _, field := range fields {
+			if *field.Value == "" {
+				continue
+			}
+			found := scan.ExcludeKnown(*field.Value, stage.scanner.Scan(field.Name, *field.Value), field.Known)
+			if len(found) > 0 && stage.action == scan.ActionRedact {
+				*field.Value = scan.Redact(*field.Value, found)
+			}
+			findings = append(findings, found...)
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		list := formatFindings(findings)
+		switch stage.action {
+		case scan.ActionBlock:
+			return nil, newToolResultError("Nothing was sent to DocBase because the content looks like it contains %s:\n%s\n%s", stage.what, list, stage.hint)
+		case scan.ActionRedact:
+			notes = append(notes, fmt.Sprintf("Redacted %s before sending:\n%s", stage.what, list))
+		default:
+			notes = append(notes, fmt.Sprintf("Warning: the content may contain %s:\n%s", stage.what, list))
+		}
+	}
+	return notes, nil
+}
+
+func formatFindings(findings []scan.Finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		lines = append(lines, "- "+f.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withNotes は結果の本文に filterContent の報告を添えます
+func withNotes(text string, notes []string) string {
+	if len(notes) == 0 {
+		return text
+	}
+	return text + "\n\n" + strings.Join(notes, "\n\n")
+}
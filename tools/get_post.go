@@ -2,12 +2,11 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"strings"
+	"time"
 
-	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/markdown"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,35 +22,154 @@ func NewGetPostTool() server.ServerTool {
 func newGetPostTool() mcp.Tool {
 	return mcp.NewTool(
 		"get_post_by_post_id",
-		mcp.WithDescription("Get post from docbase by post ID"),
+		mcp.WithDescription("Get post from docbase by post ID. "+
+			"For long posts, get the outline first and then read one section or a range of lines instead of the whole body"),
+		withPostID("The ID of the post to get"),
+		mcp.WithBoolean(
+			"outline",
+			mcp.Description("Return only the headings of the body with their line numbers"),
+		),
+		mcp.WithString(
+			"section",
+			mcp.Description("Return only the section under this heading, e.g. 'Rollback' or 'Release plan > Rollback'"),
+		),
+		withInteger(
+			"start_line",
+			mcp.Description("Return the body from this line (1-based)"),
+			mcp.Min(1),
+		),
+		withInteger(
+			"end_line",
+			mcp.Description("Return the body up to this line (default is the last line)"),
+			mcp.Min(1),
+		),
+		withInteger(
+			"char_offset",
+			mcp.Description("Return the body from this character (0-based)"),
+			mcp.Min(0),
+		),
+		withInteger(
+			"char_count",
+			mcp.Description("The number of characters to return from char_offset (default is the rest of the body)"),
+			mcp.Min(1),
+		),
 		mcp.WithString(
-			"post_id",
-			mcp.Required(),
-			mcp.Description("The ID of the post to get"),
+			"cursor",
+			mcp.Description("The next_cursor (or previous_cursor) in the meta of a long body, section or range that was returned in parts. Give it with only post_id. "+
+				"Parts are read with a cursor instead of a page number because the cursor records when the post was updated: if the post changes between calls, the call fails instead of returning a part that no longer lines up with the previous ones"),
+		),
+		withInteger(
+			"max_chars",
+			mcp.Description("The maximum number of characters of the body, section or range to return at once. Longer text is returned in parts with a cursor (default and maximum is the server's limit)"),
+			mcp.Min(1000),
 		),
+		withTeam(),
 	)
 }
 
-func handleGetPostRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client := docbase.NewDocBaseClient(
-		os.Getenv("DOCBASE_API_DOMAIN"),
-		os.Getenv("DOCBASE_API_TOKEN"),
-	)
+// getPostArgs は get_post_by_post_id の引数です
+// outline、section、行の範囲、文字の範囲は、いずれか1つだけを指定できます
+// cursor は読んでいる範囲と分割の大きさを含むため、ほかの指定とは組み合わせられません
+type getPostArgs struct {
+	PostID     int64  `arg:"post_id,required" min:"1"`
+	Outline    bool   `arg:"outline"`
+	Section    string `arg:"section"`
+	StartLine  *int   `arg:"start_line" min:"1"`
+	EndLine    *int   `arg:"end_line" min:"1"`
+	CharOffset *int   `arg:"char_offset" min:"0"`
+	CharCount  *int   `arg:"char_count" min:"1"`
+
+	Cursor   *bodyCursor `arg:"cursor"`
+	MaxChars int         `arg:"max_chars" min:"1000"`
+}
 
-	postIDString, ok := request.Params.Arguments["post_id"]
-	if !ok {
-		return nil, errors.New("post_id is required")
+// selection は本文のどの部分を返すかの指定を検証します
+func (a getPostArgs) selection() error {
+	var modes []string
+	if a.Outline {
+		modes = append(modes, "outline")
+	}
+	if a.Section != "" {
+		modes = append(modes, "section")
+	}
+	if a.StartLine != nil || a.EndLine != nil {
+		modes = append(modes, "start_line/end_line")
+	}
+	if a.CharOffset != nil || a.CharCount != nil {
+		modes = append(modes, "char_offset/char_count")
 	}
+	if len(modes) > 1 {
+		return fmt.Errorf("give only one of %s", strings.Join(modes, ", "))
+	}
+	if a.Outline && a.MaxChars != 0 {
+		return fmt.Errorf("max_chars can not be used with outline")
+	}
+	if a.Cursor != nil && (len(modes) > 0 || a.MaxChars != 0) {
+		return fmt.Errorf("cursor already holds the range and the size of the parts; give it with only post_id")
+	}
+	return nil
+}
 
-	postID, err := strconv.Atoi(postIDString.(string))
+func handleGetPostRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
 	if err != nil {
-		return nil, errors.New("post_id is not a number")
+		return newToolResultError("%v", err), nil
+	}
+
+	var args getPostArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+	if err := args.selection(); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
 	}
 
-	post, err := client.GetPost(ctx, int64(postID))
+	post, err := client.GetPost(ctx, args.PostID)
 	if err != nil {
-		return nil, err
+		return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
+	}
+	versions.remember(client, post)
+
+	// Updated at は update_post の expected_updated_at にそのまま渡せる形式で返す
+	header := fmt.Sprintf("Title: %s\nUpdated at: %s\n", post.Title, post.UpdatedAt.Format(time.RFC3339))
+
+	// 長い本文やその一部は分割して、続きをカーソルで読ませる
+	maxChars := int(maxBodyChars.Load())
+	if args.MaxChars != 0 {
+		maxChars = min(maxChars, args.MaxChars)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Title: %s\nBody: %s\n", post.Title, post.Body)), nil
+	var excerpt string
+	switch {
+	case args.Cursor != nil:
+		excerpt, err = chunkedBody(post, args.Cursor, 0, 0, maxChars, "")
+	case args.Outline:
+		excerpt = outlineExcerpt(post.Body)
+	case args.Section != "":
+		excerpt, err = sectionExcerpt(post, markdown.ParsePath(args.Section), maxChars)
+	case args.StartLine != nil || args.EndLine != nil:
+		start, end := 1, 0
+		if args.StartLine != nil {
+			start = *args.StartLine
+		}
+		if args.EndLine != nil {
+			end = *args.EndLine
+		}
+		excerpt, err = lineExcerpt(post, start, end, maxChars)
+	case args.CharOffset != nil || args.CharCount != nil:
+		offset, count := 0, 0
+		if args.CharOffset != nil {
+			offset = *args.CharOffset
+		}
+		if args.CharCount != nil {
+			count = *args.CharCount
+		}
+		excerpt, err = charExcerpt(post, offset, count, maxChars)
+	default:
+		excerpt, err = chunkedBody(post, nil, 0, len(post.Body), maxChars, "Body: %s\n")
+	}
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+	return mcp.NewToolResultText(header + excerpt), nil
 }
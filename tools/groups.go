@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// groupCacheTTL はグループの一覧をキャッシュする時間です
+const groupCacheTTL = 10 * time.Minute
+
+// maxSuggestedGroups は不明なグループ名のエラーに並べるグループの数の上限です
+const maxSuggestedGroups = 20
+
+var groupCache = docbase.NewGroupCache(groupCacheTTL)
+
+// groupRef は groups 引数の要素で、グループのIDか名前を表します
+type groupRef struct {
+	ID   int
+	Name string
+}
+
+func (g *groupRef) decodeArgument(raw interface{}) error {
+	switch v := raw.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if id, err := strconv.Atoi(v); err == nil {
+			raw = float64(id)
+			break
+		}
+		if v == "" {
+			return errors.New("must not be empty")
+		}
+		g.Name = v
+		return nil
+	}
+
+	id, err := toInt(raw)
+	if err != nil {
+		return errors.New("must be a group ID or name")
+	}
+	if id < 1 {
+		return fmt.Errorf("must be at least 1 (got %d)", id)
+	}
+	g.ID = int(id)
+	return nil
+}
+
+// groupError はグループ名を解決できなかったことを表します
+type groupError struct {
+	message string
+}
+
+func (e *groupError) Error() string {
+	return e.message
+}
+
+// resolveGroups はグループの名前をIDに解決します
+// 知らない名前があった場合は、新しく作られたグループかもしれないため一覧を取得し直してから判断します
+func resolveGroups(ctx context.Context, client *docbase.DocBaseClient, refs []groupRef) ([]int, error) {
+	var names bool
+	for _, ref := range refs {
+		if ref.Name != "" {
+			names = true
+		}
+	}
+	if !names {
+		ids := make([]int, 0, len(refs))
+		for _, ref := range refs {
+			ids = append(ids, ref.ID)
+		}
+		return ids, nil
+	}
+
+	groups, err := groupCache.Groups(ctx, client, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := matchGroups(groups, refs)
+
+	var gErr *groupError
+	if errors.As(err, &gErr) {
+		groups, err = groupCache.Groups(ctx, client, true)
+		if err != nil {
+			return nil, err
+		}
+		ids, err = matchGroups(groups, refs)
+	}
+	return ids, err
+}
+
+func matchGroups(groups []docbase.Group, refs []groupRef) ([]int, error) {
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Name == "" {
+			ids = append(ids, ref.ID)
+			continue
+		}
+
+		// 完全に一致する名前を優先し、なければ大文字と小文字を区別せずに探す
+		var exact, folded []docbase.Group
+		for _, g := range groups {
+			switch {
+			case g.Name == ref.Name:
+				exact = append(exact, g)
+			case strings.EqualFold(g.Name, ref.Name):
+				folded = append(folded, g)
+			}
+		}
+		matches := exact
+		if len(matches) == 0 {
+			matches = folded
+		}
+
+		switch len(matches) {
+		case 0:
+			return nil, &groupError{fmt.Sprintf("unknown group %q (groups: %s)", ref.Name, groupNames(groups))}
+		case 1:
+			ids = append(ids, matches[0].ID)
+		default:
+			candidates := make([]string, 0, len(matches))
+			for _, g := range matches {
+				candidates = append(candidates, fmt.Sprintf("%q (ID %d)", g.Name, g.ID))
+			}
+			return nil, &groupError{fmt.Sprintf("group name %q is ambiguous: it matches %s. Pass the group ID instead", ref.Name, strings.Join(candidates, ", "))}
+		}
+	}
+	return ids, nil
+}
+
+// groupNames はエラーメッセージに使うグループ名の一覧を返します
+func groupNames(groups []docbase.Group) string {
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	sort.Strings(names)
+	if len(names) > maxSuggestedGroups {
+		names = append(names[:maxSuggestedGroups], "...")
+	}
+	return strings.Join(names, ", ")
+}
+
+// groupErrorResult はグループの解決に失敗したときのツールの結果を作成します
+func groupErrorResult(err error) *mcp.CallToolResult {
+	var gErr *groupError
+	if errors.As(err, &gErr) {
+		return newToolResultError("Invalid arguments: groups %v", gErr)
+	}
+	return apiErrorResult(err, "the group list")
+}
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"docbase-mcp-server/docbase"
+)
+
+func TestMatchGroups(t *testing.T) {
+	groups := []docbase.Group{
+		{ID: 1, Name: "backend-team"},
+		{ID: 2, Name: "Design"},
+		{ID: 3, Name: "design"},
+		{ID: 4, Name: "Sales"},
+	}
+
+	var refs []groupRef
+	for _, raw := range []interface{}{"backend-team", float64(7), "12", "sales", "design"} {
+		var ref groupRef
+		if err := ref.decodeArgument(raw); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	ids, err := matchGroups(groups, refs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []int{1, 7, 12, 4, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, but got %v", want, ids)
+	}
+
+	if _, err := matchGroups(groups, []groupRef{{Name: "DESIGN"}}); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguous name error, but got %v", err)
+	}
+	if _, err := matchGroups(groups, []groupRef{{Name: "frontend"}}); err == nil || !strings.Contains(err.Error(), `unknown group "frontend"`) {
+		t.Errorf("Expected an unknown group error, but got %v", err)
+	}
+}
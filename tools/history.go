@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/state"
+)
+
+// maxHistoryVersions は投稿ごとに残す履歴の版の数です。古いものから削除します
+const maxHistoryVersions = 50
+
+// historyVersion は更新する前の投稿の版です
+type historyVersion struct {
+	// Version は投稿ごとに1から順に振る番号です。古い版を削除しても番号は振り直しません
+	Version   int       `json:"version"`
+	SavedAt   time.Time `json:"saved_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Tool はこの版を書き換えたツールの名前です
+	Tool  string        `json:"tool"`
+	Title string        `json:"title"`
+	Body  string        `json:"body"`
+	Tags  []string      `json:"tags"`
+	Draft bool          `json:"draft"`
+	Scope docbase.Scope `json:"scope"`
+}
+
+type postHistory struct {
+	Versions []historyVersion `json:"versions"`
+}
+
+// historyFiles は投稿ごとの履歴のファイルです。同じファイルへの書き込みを1つずつ行うため使い回します
+var historyFiles = make(map[string]*state.File)
+
+// historyFile は client のチームとトークンで読み書きした postID の投稿の履歴のファイルを返します
+// 状態を保存するディレクトリが設定されていない場合は nil を返します
+func historyFile(client *docbase.DocBaseClient, postID int64) *state.File {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if stateDir == "" {
+		return nil
+	}
+	dir := filepath.Join(stateDir, "history", accountID(client))
+	name := fmt.Sprintf("%d.json", postID)
+	key := filepath.Join(dir, name)
+	if f, ok := historyFiles[key]; ok {
+		return f
+	}
+	f := state.NewFile(dir, name)
+	historyFiles[key] = f
+	return f
+}
+
+// saveHistory は tool で更新する前の投稿 post を履歴に追加し、追加した版を返します
+// 直前の版と同じ updated_at の場合は追加せずにその版を返します。履歴を保存しない設定の場合は nil を返します
+func saveHistory(client *docbase.DocBaseClient, post *docbase.GetPostResponse, tool string) (*historyVersion, error) {
+	f := historyFile(client, post.PostID)
+	if f == nil {
+		return nil, nil
+	}
+
+	var history postHistory
+	var saved historyVersion
+	err := f.Update(&history, func() error {
+		n := len(history.Versions)
+		if n > 0 && history.Versions[n-1].UpdatedAt.Equal(post.UpdatedAt) {
+			saved = history.Versions[n-1]
+			return nil
+		}
+
+		saved = historyVersion{
+			Version:   1,
+			SavedAt:   time.Now(),
+			UpdatedAt: post.UpdatedAt,
+			Tool:      tool,
+			Title:     post.Title,
+			Body:      post.Body,
+			Draft:     post.Draft,
+			Scope:     post.Scope,
+		}
+		if n > 0 {
+			saved.Version = history.Versions[n-1].Version + 1
+		}
+		for _, tag := range post.Tags {
+			saved.Tags = append(saved.Tags, tag.Name)
+		}
+		history.Versions = append(history.Versions, saved)
+		if len(history.Versions) > maxHistoryVersions {
+			history.Versions = history.Versions[len(history.Versions)-maxHistoryVersions:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// loadHistory は postID の投稿の履歴を古い順に返します
+func loadHistory(client *docbase.DocBaseClient, postID int64) ([]historyVersion, error) {
+	f := historyFile(client, postID)
+	if f == nil {
+		return nil, nil
+	}
+	var history postHistory
+	if err := f.Load(&history); err != nil {
+		return nil, err
+	}
+	return history.Versions, nil
+}
+
+// findHistoryVersion は postID の投稿の履歴から version の版を探します
+func findHistoryVersion(client *docbase.DocBaseClient, postID int64, version int) (*historyVersion, error) {
+	history, err := loadHistory(client, postID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		if history[i].Version == version {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("version %d of post %d is not in the local history (see list_post_history)", version, postID)
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+)
+
+func TestSaveHistory(t *testing.T) {
+	client := docbase.NewDocBaseClient("example", "token")
+
+	// 状態を保存するディレクトリがなければ何も保存しない
+	SetStateDir("")
+	if saved, err := saveHistory(client, &docbase.GetPostResponse{PostID: 1}, "update_post"); err != nil || saved != nil {
+		t.Fatalf("Expected nothing to be saved, but got %v, %v", saved, err)
+	}
+
+	SetStateDir(t.TempDir())
+	defer SetStateDir("")
+
+	updatedAt := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	for i := 0; i < maxHistoryVersions+2; i++ {
+		post := &docbase.GetPostResponse{
+			PostID:    1,
+			Title:     "title",
+			Body:      "body",
+			Tags:      []docbase.Tag{{Name: "手順"}},
+			UpdatedAt: updatedAt.Add(time.Duration(i) * time.Minute),
+		}
+		saved, err := saveHistory(client, post, "update_post")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if saved.Version != i+1 {
+			t.Errorf("Expected version %d, but got %d", i+1, saved.Version)
+		}
+	}
+
+	// 更新に失敗して同じ版をもう一度保存しても、版は増えない
+	last := &docbase.GetPostResponse{PostID: 1, UpdatedAt: updatedAt.Add((maxHistoryVersions + 1) * time.Minute)}
+	if saved, err := saveHistory(client, last, "update_post"); err != nil || saved.Version != maxHistoryVersions+2 {
+		t.Errorf("Expected the last version to be reused, but got %v, %v", saved, err)
+	}
+
+	history, err := loadHistory(client, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != maxHistoryVersions || history[0].Version != 3 {
+		t.Errorf("Expected versions 3 to %d, but got %d versions from %d", maxHistoryVersions+2, len(history), history[0].Version)
+	}
+	if history[0].Tags[0] != "手順" {
+		t.Errorf("Expected the tags to be saved, but got %v", history[0].Tags)
+	}
+
+	if _, err := findHistoryVersion(client, 1, 1); err == nil {
+		t.Error("Expected an error for a pruned version")
+	}
+	if _, err := findHistoryVersion(docbase.NewDocBaseClient("example", "other-token"), 1, 3); err == nil {
+		t.Error("Expected the history of another token not to be visible")
+	}
+}
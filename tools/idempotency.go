@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/state"
+)
+
+const (
+	// idempotencyKeyTTL は idempotency_key を覚えておく期間です
+	idempotencyKeyTTL = 24 * time.Hour
+	// duplicateWindow は idempotency_key のない create_post で、同じタイトルと本文の投稿を重複とみなす期間です
+	duplicateWindow = 5 * time.Minute
+)
+
+var (
+	stateMu sync.RWMutex
+	// stateDir は記録を保存するディレクトリです。空の場合は何も保存しません
+	stateDir string
+	// createdPosts はこのサーバーが作成した投稿の記録です
+	createdPosts = state.NewFile("", "")
+)
+
+var (
+	creatingMu sync.Mutex
+	// creating は作成中の投稿です。再試行が最初の呼び出しの作成中に届いても、二重に作成しないようにします
+	creating = make(map[string]bool)
+)
+
+// SetStateDir は書き込み系のツールが作成した投稿の記録や投稿の履歴を保存するディレクトリを登録します
+// 空の場合は何も保存しません。起動時に一度呼び出します
+func SetStateDir(dir string) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	stateDir = dir
+	historyFiles = make(map[string]*state.File)
+	createdPosts = state.NewFile(dir, "created_posts.json")
+}
+
+func createdPostsFile() *state.File {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return createdPosts
+}
+
+// createdPost は create_post で作成した投稿の記録です
+// 重複の判定にだけ使うため、タイトルと本文はハッシュだけを保存します
+type createdPost struct {
+	Account   string    `json:"account"`
+	Key       string    `json:"idempotency_key,omitempty"`
+	Hash      string    `json:"hash"`
+	PostID    int64     `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Notice は作成したときに通知したかどうかです
+	Notice bool `json:"notice"`
+}
+
+type createdPostsRecord struct {
+	Posts []createdPost `json:"posts"`
+}
+
+// contentHash はタイトルと本文の組を識別するハッシュを返します
+func contentHash(title, body string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+// accountID はチームとAPIトークンの組を識別する値を返します
+// ユーザーごとに別のトークンを使う場合に、他人の作成した投稿を返さないようにするためです
+func accountID(client *docbase.DocBaseClient) string {
+	sum := sha256.Sum256([]byte(client.Domain + "\x00" + client.APIToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// reserveCreation は account で key (空の場合は hash) の投稿の作成を始めることを記録します
+// 同じ投稿を作成中の場合は false を返します。作成を記録し終えたら release を呼び出します
+func reserveCreation(account, key, hash string) (release func(), ok bool) {
+	id := account + "\x00hash\x00" + hash
+	if key != "" {
+		id = account + "\x00key\x00" + key
+	}
+
+	creatingMu.Lock()
+	defer creatingMu.Unlock()
+	if creating[id] {
+		return nil, false
+	}
+	creating[id] = true
+	return func() {
+		creatingMu.Lock()
+		defer creatingMu.Unlock()
+		delete(creating, id)
+	}, true
+}
+
+// findCreatedPost は account で key または hash が一致する作成済みの投稿を探します
+// key が空の場合は、duplicateWindow 以内に同じ hash で作成した投稿を探します
+func findCreatedPost(account, key, hash string, now time.Time) (*createdPost, error) {
+	var record createdPostsRecord
+	if err := createdPostsFile().Load(&record); err != nil {
+		return nil, err
+	}
+
+	// 新しいものから探す
+	for i := len(record.Posts) - 1; i >= 0; i-- {
+		p := record.Posts[i]
+		if p.Account != account {
+			continue
+		}
+		switch {
+		case key != "":
+			if p.Key == key && now.Sub(p.CreatedAt) < idempotencyKeyTTL {
+				return &p, nil
+			}
+		case p.Hash == hash && now.Sub(p.CreatedAt) < duplicateWindow:
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+// recordCreatedPost は作成した投稿を記録し、期限の切れた記録を削除します
+func recordCreatedPost(p createdPost) error {
+	var record createdPostsRecord
+	return createdPostsFile().Update(&record, func() error {
+		posts := record.Posts[:0]
+		for _, old := range record.Posts {
+			if p.CreatedAt.Sub(old.CreatedAt) < idempotencyKeyTTL {
+				posts = append(posts, old)
+			}
+		}
+		record.Posts = append(posts, p)
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindCreatedPost(t *testing.T) {
+	SetStateDir(t.TempDir())
+	defer SetStateDir("")
+
+	now := time.Now()
+	hash := contentHash("title", "body")
+	for _, p := range []createdPost{
+		{Account: "a", Key: "key-1", Hash: hash, PostID: 1, CreatedAt: now.Add(-time.Hour)},
+		{Account: "a", Hash: hash, PostID: 2, CreatedAt: now.Add(-time.Minute)},
+		{Account: "b", Key: "key-2", Hash: hash, PostID: 3, CreatedAt: now},
+	} {
+		if err := recordCreatedPost(p); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		account string
+		key     string
+		hash    string
+		now     time.Time
+		want    int64
+	}{
+		{"same key", "a", "key-1", contentHash("other", "body"), now, 1},
+		{"key of another account", "a", "key-2", hash, now, 0},
+		{"expired key", "a", "key-1", hash, now.Add(idempotencyKeyTTL), 0},
+		{"same content", "a", "", hash, now, 2},
+		{"same content after the window", "a", "", hash, now.Add(duplicateWindow), 0},
+		{"different content", "a", "", contentHash("title", "other"), now, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := findCreatedPost(tt.account, tt.key, tt.hash, tt.now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got int64
+			if p != nil {
+				got = p.PostID
+			}
+			if got != tt.want {
+				t.Errorf("Expected post %d, but got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReserveCreation(t *testing.T) {
+	release, ok := reserveCreation("a", "key-1", "hash")
+	if !ok {
+		t.Fatal("Expected the first reservation to succeed")
+	}
+	if _, ok := reserveCreation("a", "key-1", "other"); ok {
+		t.Error("Expected a reservation with the same key to fail while the first one is held")
+	}
+
+	// 別のキー、別のアカウント、キーのない同じ内容は別の投稿として扱う
+	for _, r := range []struct{ account, key, hash string }{{"a", "key-2", "hash"}, {"b", "key-1", "hash"}, {"a", "", "hash"}} {
+		other, ok := reserveCreation(r.account, r.key, r.hash)
+		if !ok {
+			t.Errorf("Expected the reservation %v to succeed", r)
+			continue
+		}
+		if _, ok := reserveCreation(r.account, r.key, r.hash); ok {
+			t.Errorf("Expected a second reservation %v to fail", r)
+		}
+		other()
+	}
+
+	release()
+	if release, ok := reserveCreation("a", "key-1", "hash"); !ok {
+		t.Error("Expected a reservation to succeed after the first one was released")
+	} else {
+		release()
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewListPostHistoryTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newListPostHistoryTool(),
+		Handler: handleListPostHistoryRequest,
+	}
+}
+
+func newListPostHistoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_post_history",
+		mcp.WithDescription("List the versions of a DocBase post saved locally before each update made through this server. "+
+			"Use diff_post_versions to compare them and restore_post_version to roll back an edit"),
+		withPostID("The ID of the post"),
+		withTeam(),
+	)
+}
+
+// listPostHistoryArgs は list_post_history の引数です
+type listPostHistoryArgs struct {
+	PostID int64 `arg:"post_id,required" min:"1"`
+}
+
+func handleListPostHistoryRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args listPostHistoryArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+
+	history, err := loadHistory(client, args.PostID)
+	if err != nil {
+		return newToolResultError("Failed to read the local history: %v", err), nil
+	}
+	if len(history) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No local history for post %d. Only updates made through this server are recorded.", args.PostID)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Local history of post %d (%d versions, newest first):", args.PostID, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		v := history[i]
+		fmt.Fprintf(&b, "\nVersion %d: saved at %s before %s\n  Title: %s\n  Updated at: %s, %d lines, scope %s",
+			v.Version, v.SavedAt.Format(time.RFC3339), v.Tool, v.Title, v.UpdatedAt.Format(time.RFC3339), strings.Count(v.Body, "\n")+1, v.Scope)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
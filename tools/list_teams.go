@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewListTeamsTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newListTeamsTool(),
+		Handler: handleListTeamsRequest,
+	}
+}
+
+func newListTeamsTool() mcp.Tool {
+	return mcp.NewTool(
+		"list_teams",
+		mcp.WithDescription("List the DocBase teams that can be passed as the team argument of the other tools"),
+	)
+}
+
+func handleListTeamsRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	teams, err := docbase.ConfiguredTeams()
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	// APIトークンは返さない
+	type team struct {
+		Name    string `json:"name"`
+		Domain  string `json:"domain"`
+		Default bool   `json:"default"`
+	}
+	result := []team{}
+	for _, t := range teams.List() {
+		result = append(result, team{
+			Name:    t.Name,
+			Domain:  t.Domain,
+			Default: t.Name == teams.Default(),
+		})
+	}
+
+	jsonResponse, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(jsonResponse)), nil
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"docbase-mcp-server/textdiff"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewPatchPostTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newPatchPostTool(),
+		Handler: handlePatchPostRequest,
+	}
+}
+
+func newPatchPostTool() mcp.Tool {
+	return mcp.NewTool(
+		"patch_post",
+		mcp.WithDescription("Change part of the body of a DocBase post without resending the whole body. "+
+			"Give either a unified diff against the current body, or a list of exact search/replace edits. "+
+			"Nothing is changed if a diff's context lines or an edit's old_text do not match"),
+		withPostID("The ID of the post to patch"),
+		mcp.WithString(
+			"diff",
+			mcp.Description("A unified diff against the current body, with @@ -start,count +start,count @@ hunk headers and a few unchanged context lines around each change"),
+		),
+		mcp.WithArray(
+			"edits",
+			mcp.Description("Edits applied in order. Each old_text must appear exactly once in the body, unless replace_all is true"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"old_text":    map[string]interface{}{"type": "string", "description": "The exact text to replace, including enough surrounding text to be unique"},
+					"new_text":    map[string]interface{}{"type": "string", "description": "The replacement text (empty to delete old_text)"},
+					"replace_all": map[string]interface{}{"type": "boolean", "description": "Replace every occurrence of old_text (default is false)"},
+				},
+				"required": []string{"old_text", "new_text"},
+			}),
+		),
+		mcp.WithBoolean(
+			"notice",
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
+		),
+		withExpectedUpdatedAt(),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
+	)
+}
+
+// patchPostArgs は patch_post の引数です
+type patchPostArgs struct {
+	PostID int64      `arg:"post_id,required" min:"1"`
+	Diff   string     `arg:"diff"`
+	Edits  []textEdit `arg:"edits"`
+	Notice *bool      `arg:"notice"`
+	DryRun bool       `arg:"dry_run"`
+
+	ExpectedUpdatedAt *timestamp `arg:"expected_updated_at"`
+}
+
+// textEdit は完全に一致する文字列の置き換えです
+type textEdit struct {
+	OldText    string
+	NewText    string
+	ReplaceAll bool
+}
+
+func (e *textEdit) decodeArgument(raw interface{}) error {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return errors.New("must be a list of objects with old_text and new_text")
+	}
+	oldText, _ := m["old_text"].(string)
+	if oldText == "" {
+		return errors.New("old_text must not be empty")
+	}
+	newText, ok := m["new_text"].(string)
+	if !ok && m["new_text"] != nil {
+		return errors.New("new_text must be a string")
+	}
+	e.OldText, e.NewText = oldText, newText
+	if v, ok := m["replace_all"]; ok && v != nil {
+		all, err := toBool(v)
+		if err != nil {
+			return fmt.Errorf("replace_all %v", err)
+		}
+		e.ReplaceAll = all
+	}
+	return nil
+}
+
+// applyEdits は edits を順に body に適用します
+func applyEdits(body string, edits []textEdit) (string, error) {
+	for i, e := range edits {
+		n := strings.Count(body, e.OldText)
+		switch {
+		case n == 0:
+			return "", fmt.Errorf("edit %d: old_text %q was not found in the body", i+1, shorten(e.OldText))
+		case n > 1 && !e.ReplaceAll:
+			return "", fmt.Errorf("edit %d: old_text %q appears %d times in the body; include more surrounding text, or set replace_all", i+1, shorten(e.OldText), n)
+		}
+		body = strings.ReplaceAll(body, e.OldText, e.NewText)
+	}
+	return body, nil
+}
+
+// shorten はエラーに含める文字列を短くします
+func shorten(s string) string {
+	const limit = 80
+	if r := []rune(s); len(r) > limit {
+		return string(r[:limit]) + "…"
+	}
+	return s
+}
+
+func handlePatchPostRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args patchPostArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+	if (args.Diff == "") == (len(args.Edits) == 0) {
+		return newToolResultError("Invalid arguments: give either diff or edits"), nil
+	}
+
+	edit := func(body string) (string, error) {
+		if args.Diff != "" {
+			return textdiff.Apply(body, args.Diff)
+		}
+		return applyEdits(body, args.Edits)
+	}
+
+	result, _ := updatePost(ctx, request, client, policy, updatePostArgs{
+		PostID:            args.PostID,
+		Notice:            args.Notice,
+		DryRun:            args.DryRun,
+		ExpectedUpdatedAt: args.ExpectedUpdatedAt,
+		edit:              edit,
+	}, "Post patched successfully!")
+	return result, nil
+}
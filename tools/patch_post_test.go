@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEdits(t *testing.T) {
+	var args patchPostArgs
+	err := bindArguments(map[string]interface{}{
+		"post_id": float64(1),
+		"edits": []interface{}{
+			map[string]interface{}{"old_text": "- [ ] レビュー", "new_text": "- [x] レビュー"},
+			map[string]interface{}{"old_text": "TODO", "new_text": "", "replace_all": true},
+		},
+	}, &args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	body := "## タスク\n- [ ] レビュー TODO\n- [ ] リリース TODO\n"
+	got, err := applyEdits(body, args.Edits)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "## タスク\n- [x] レビュー \n- [ ] リリース \n"; got != want {
+		t.Errorf("Expected %q, but got %q", want, got)
+	}
+
+	tests := []struct {
+		name    string
+		edit    textEdit
+		wantErr string
+	}{
+		{"not found", textEdit{OldText: "- [ ] デプロイ"}, `edit 1: old_text "- [ ] デプロイ" was not found`},
+		{"ambiguous", textEdit{OldText: "- [ ]"}, "appears 2 times"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyEdits(body, []textEdit{tt.edit})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	err = bindArguments(map[string]interface{}{"post_id": float64(1), "edits": []interface{}{map[string]interface{}{"new_text": "x"}}}, &args)
+	if err == nil || !strings.Contains(err.Error(), "old_text must not be empty") {
+		t.Errorf("Expected an error for a missing old_text, but got %v", err)
+	}
+}
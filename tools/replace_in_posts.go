@@ -0,0 +1,317 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"docbase-mcp-server/docbase"
+	"docbase-mcp-server/textdiff"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxReplaceTargets は replace_in_posts が一度に対象にする投稿の最大数です
+const maxReplaceTargets = 100
+
+func NewReplaceInPostsTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newReplaceInPostsTool(),
+		Handler: handleReplaceInPostsRequest,
+	}
+}
+
+func newReplaceInPostsTool() mcp.Tool {
+	return mcp.NewTool(
+		"replace_in_posts",
+		mcp.WithDescription("Find and replace text in the bodies of several DocBase posts at once, e.g. to rename a service across documents. "+
+			"The posts are given by a search query or a list of IDs. "+
+			"Run it with dry_run first to see the number of matches and a diff for each post"),
+		mcp.WithString(
+			"query",
+			mcp.Description("A search query selecting the posts (the same syntax as search_posts). Give either query or post_ids"),
+		),
+		mcp.WithArray(
+			"post_ids",
+			mcp.Description(fmt.Sprintf("The IDs of the posts (at most %d). Give either query or post_ids", maxReplaceTargets)),
+			mcp.Items(map[string]interface{}{"type": "integer"}),
+		),
+		mcp.WithString(
+			"pattern",
+			mcp.Required(),
+			mcp.Description("The text to find. It is matched literally unless regex is true"),
+		),
+		mcp.WithString(
+			"replacement",
+			mcp.Required(),
+			mcp.Description("The replacement text (an empty string deletes the matches). With regex, $1 or ${name} insert the groups of the match"),
+		),
+		mcp.WithBoolean(
+			"regex",
+			mcp.Description("Treat pattern as a regular expression (RE2 syntax; ^ and $ match at the start and end of each line). Default is false"),
+		),
+		mcp.WithBoolean(
+			"ignore_case",
+			mcp.Description("Match pattern case-insensitively (default is false)"),
+		),
+		withInteger(
+			"max_posts",
+			mcp.Min(1),
+			mcp.Max(maxReplaceTargets),
+			mcp.DefaultNumber(20),
+			mcp.Description(fmt.Sprintf("The number of search results to check when query is given (default is 20, max is %d)", maxReplaceTargets)),
+		),
+		mcp.WithBoolean(
+			"notice",
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
+		),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
+	)
+}
+
+// replaceInPostsArgs は replace_in_posts の引数です
+type replaceInPostsArgs struct {
+	Query       string  `arg:"query"`
+	PostIDs     []int64 `arg:"post_ids"`
+	Pattern     string  `arg:"pattern,required,verbatim"`
+	Replacement string  `arg:"replacement,verbatim"` // 空白だけの置き換えや空文字列での削除もできる
+	Regex       bool    `arg:"regex"`
+	IgnoreCase  bool    `arg:"ignore_case"`
+	MaxPosts    int     `arg:"max_posts" default:"20" min:"1" max:"100"`
+	Notice      *bool   `arg:"notice"`
+	DryRun      bool    `arg:"dry_run"`
+}
+
+// replacer は本文の中の pattern に一致する部分を置き換えます
+type replacer struct {
+	re          *regexp.Regexp
+	replacement string
+	// literal が true の場合は replacement の $ をそのまま挿入します
+	literal bool
+}
+
+func newReplacer(pattern, replacement string, regex, ignoreCase bool) (*replacer, error) {
+	expr := pattern
+	if !regex {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	flags := "(?m)"
+	if ignoreCase {
+		flags = "(?mi)"
+	}
+	re, err := regexp.Compile(flags + expr)
+	if err != nil {
+		return nil, fmt.Errorf("pattern is not a valid regular expression: %v", err)
+	}
+	// 空文字列に一致するパターンは、すべての位置に挿入してしまう
+	if re.MatchString("") {
+		return nil, errors.New("pattern must not match an empty string")
+	}
+	return &replacer{re: re, replacement: replacement, literal: !regex}, nil
+}
+
+// replace は body の一致する部分をすべて置き換えた本文と、置き換えた数を返します
+func (r *replacer) replace(body string) (string, int) {
+	n := len(r.re.FindAllStringIndex(body, -1))
+	if n == 0 {
+		return body, 0
+	}
+	if r.literal {
+		return r.re.ReplaceAllLiteralString(body, r.replacement), n
+	}
+	return r.re.ReplaceAllString(body, r.replacement), n
+}
+
+// replaceTarget は置き換えの対象の投稿と、その本文で一致した数です
+type replaceTarget struct {
+	post    *docbase.GetPostResponse
+	matches int
+}
+
+func handleReplaceInPostsRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args replaceInPostsArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+	// 空文字列の replacement は省略と区別できないため、キーがあるかで判断する
+	if _, ok := request.Params.Arguments["replacement"]; !ok {
+		return newToolResultError("Invalid arguments: replacement is required (pass an empty string to delete the matches)"), nil
+	}
+	if (args.Query == "") == (len(args.PostIDs) == 0) {
+		return newToolResultError("Invalid arguments: give either query or post_ids"), nil
+	}
+	if len(args.PostIDs) > maxReplaceTargets {
+		return newToolResultError("Invalid arguments: post_ids must have at most %d items (got %d)", maxReplaceTargets, len(args.PostIDs)), nil
+	}
+	r, err := newReplacer(args.Pattern, args.Replacement, args.Regex, args.IgnoreCase)
+	if err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+
+	posts, notes, failures := replaceCandidates(ctx, client, args)
+	if posts == nil && len(failures) > 0 {
+		return newToolResultError("%s", strings.Join(failures, "\n")), nil
+	}
+
+	var targets []replaceTarget
+	var unmatched []string
+	for _, post := range posts {
+		versions.remember(client, post)
+		if _, n := r.replace(post.Body); n > 0 {
+			targets = append(targets, replaceTarget{post: post, matches: n})
+		} else {
+			unmatched = append(unmatched, fmt.Sprintf("%d", post.PostID))
+		}
+	}
+	if len(unmatched) > 0 {
+		notes = append(notes, fmt.Sprintf("No matches in %d posts: %s.", len(unmatched), strings.Join(unmatched, ", ")))
+	}
+	if len(targets) == 0 {
+		return mcp.NewToolResultText(withNotes(
+			fmt.Sprintf("No matches for %q in %d posts. Nothing was changed.", args.Pattern, len(posts)),
+			append(failures, notes...))), nil
+	}
+
+	notice := policy.DefaultNotice
+	if args.Notice != nil {
+		notice = *args.Notice
+	}
+	reasons := visibilityChanges("", nil, "", nil, notice)
+
+	if isDryRun(args.DryRun) {
+		return replacePreview(args, r, targets, reasons, append(failures, notes...)), nil
+	}
+
+	// 投稿ごとに確認を求めないよう、通知する場合はまとめて一度だけ確認する
+	if result := requireConfirmation(request, reasons); result != nil {
+		return result, nil
+	}
+
+	var b strings.Builder
+	updated, replaced, backups := 0, 0, 0
+	for _, t := range targets {
+		fmt.Fprintf(&b, "\nPost %d %q: ", t.post.PostID, t.post.Title)
+
+		// 読んだ後に更新されていれば、置き換えた本文を今の本文とマージする
+		// 置き換えは updatePost が読み直した版の本文に行うため、数もそこで数える
+		matches := 0
+		result, saved := updatePost(ctx, request, client, policy, updatePostArgs{
+			PostID:            t.post.PostID,
+			Notice:            &notice,
+			ExpectedUpdatedAt: &timestamp{Time: t.post.UpdatedAt},
+			edit: func(body string) (string, error) {
+				replacedBody, n := r.replace(body)
+				if n == 0 {
+					return "", errors.New("the pattern no longer matches the body")
+				}
+				matches = n
+				return replacedBody, nil
+			},
+			confirmed: true,
+		}, "Post updated successfully!")
+		if result.IsError {
+			fmt.Fprintf(&b, "failed: %s", strings.ReplaceAll(toolResultText(result), "\n", "\n  "))
+			continue
+		}
+
+		updated++
+		replaced += matches
+		fmt.Fprintf(&b, "replaced %d matches", matches)
+		if saved != nil {
+			backups++
+			fmt.Fprintf(&b, " (the previous version is version %d of the local history)", saved.Version)
+		}
+	}
+
+	summary := fmt.Sprintf("Replaced %d matches of %q in %d of %d posts.", replaced, args.Pattern, updated, len(targets))
+	if updated < len(targets) {
+		summary += fmt.Sprintf(" %d posts failed and were not changed.", len(targets)-updated)
+	}
+	if backups > 0 {
+		notes = append(notes, "Use restore_post_version to undo the change to a post.")
+	}
+	return mcp.NewToolResultText(withNotes(summary+b.String(), append(failures, notes...))), nil
+}
+
+// replaceCandidates は args の検索クエリか ID の投稿を読みます
+// 読めなかった投稿は failures に含めます。検索に失敗した場合は posts が nil です
+func replaceCandidates(ctx context.Context, client *docbase.DocBaseClient, args replaceInPostsArgs) (posts []*docbase.GetPostResponse, notes, failures []string) {
+	if args.Query != "" {
+		result, err := client.SearchPosts(ctx, docbase.SearchQuery{Q: args.Query, Page: 1, PerPage: args.MaxPosts})
+		if err != nil {
+			return nil, nil, []string{toolResultText(apiErrorResult(err, "the search"))}
+		}
+		posts = make([]*docbase.GetPostResponse, 0, len(result.Posts))
+		for i := range result.Posts {
+			posts = append(posts, &result.Posts[i])
+		}
+		if result.Meta.Total > len(posts) {
+			notes = append(notes, fmt.Sprintf("The search matched %d posts, and only the first %d were checked. Narrow the query or raise max_posts.",
+				result.Meta.Total, len(posts)))
+		}
+		return posts, notes, nil
+	}
+
+	posts = make([]*docbase.GetPostResponse, 0, len(args.PostIDs))
+	for _, id := range args.PostIDs {
+		post, err := client.GetPost(ctx, id)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("Post %d was skipped: %s", id, toolResultText(apiErrorResult(err, fmt.Sprintf("post %d", id)))))
+			continue
+		}
+		posts = append(posts, post)
+	}
+	if len(posts) == 0 {
+		return nil, nil, failures
+	}
+	return posts, nil, failures
+}
+
+// replacePreview はドライランで、投稿ごとの一致した数と本文の差分を返します
+func replacePreview(args replaceInPostsArgs, r *replacer, targets []replaceTarget, reasons, notes []string) *mcp.CallToolResult {
+	var b strings.Builder
+	b.WriteString("Dry run: nothing was sent to DocBase.\n")
+	total := 0
+	for _, t := range targets {
+		total += t.matches
+	}
+	fmt.Fprintf(&b, "%d matches of %q would be replaced in %d posts.", total, args.Pattern, len(targets))
+
+	for _, t := range targets {
+		body, _ := r.replace(t.post.Body)
+		diff := textdiff.Unified(t.post.Body, body,
+			fmt.Sprintf("post %d (current)", t.post.PostID), fmt.Sprintf("post %d (replaced)", t.post.PostID), 2)
+		fmt.Fprintf(&b, "\n\nPost %d %q: %d matches\n%s", t.post.PostID, t.post.Title, t.matches, strings.TrimSuffix(diff, "\n"))
+	}
+	if len(reasons) > 0 {
+		fmt.Fprintf(&b, "\n\nWithout dry_run, this call would ask for confirmation because it would:\n- %s", strings.Join(reasons, "\n- "))
+	}
+	notes = append(notes, "Secrets and personal information are checked for each post when the changes are applied.")
+	return mcp.NewToolResultText(withNotes(b.String(), notes))
+}
+
+// toolResultText はツールの結果のテキストをつなげて返します
+func toolResultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range result.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
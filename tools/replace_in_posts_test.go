@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReplacer(t *testing.T) {
+	body := "# Foo API\nFoo は $HOME の下に置きます。\nfoo-client を使います。\nFoobar は別物です。\n"
+
+	tests := []struct {
+		name        string
+		pattern     string
+		replacement string
+		regex       bool
+		ignoreCase  bool
+		want        string
+		wantN       int
+	}{
+		{
+			name:        "literal",
+			pattern:     "$HOME",
+			replacement: "$XDG_DATA_HOME",
+			want:        "# Foo API\nFoo は $XDG_DATA_HOME の下に置きます。\nfoo-client を使います。\nFoobar は別物です。\n",
+			wantN:       1,
+		},
+		{
+			name:        "regex with groups",
+			pattern:     `\bFoo\b( API)?`,
+			replacement: "Bar$1",
+			regex:       true,
+			want:        "# Bar API\nBar は $HOME の下に置きます。\nfoo-client を使います。\nFoobar は別物です。\n",
+			wantN:       2,
+		},
+		{
+			name:        "ignore case",
+			pattern:     "foo",
+			replacement: "bar",
+			ignoreCase:  true,
+			want:        "# bar API\nbar は $HOME の下に置きます。\nbar-client を使います。\nbarbar は別物です。\n",
+			wantN:       4,
+		},
+		{
+			name:        "line anchors",
+			pattern:     `^Foo`,
+			replacement: "",
+			regex:       true,
+			want:        "# Foo API\n は $HOME の下に置きます。\nfoo-client を使います。\nbar は別物です。\n",
+			wantN:       2,
+		},
+		{
+			name:    "no match",
+			pattern: "Baz",
+			want:    body,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newReplacer(tt.pattern, tt.replacement, tt.regex, tt.ignoreCase)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, n := r.replace(body)
+			if got != tt.want || n != tt.wantN {
+				t.Errorf("Expected %q (%d matches), but got %q (%d matches)", tt.want, tt.wantN, got, n)
+			}
+		})
+	}
+
+	for pattern, wantErr := range map[string]string{
+		"(foo":  "not a valid regular expression",
+		"x*":    "must not match an empty string",
+		"^$":    "must not match an empty string",
+		"foo|":  "must not match an empty string",
+		"a**":   "not a valid regular expression",
+		"[a-z]": "",
+	} {
+		_, err := newReplacer(pattern, "", true, false)
+		if wantErr == "" {
+			if err != nil {
+				t.Errorf("Unexpected error for %q: %v", pattern, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Expected an error containing %q for %q, but got %v", wantErr, pattern, err)
+		}
+	}
+}
+
+func TestReplaceWhitespace(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+	fake := newFakeDocBase(t, docbase.GetPostResponse{
+		PostID:    1,
+		Title:     "手順",
+		Body:      "1.  clone\n2.  build\n",
+		Scope:     docbase.ScopePrivate,
+		UpdatedAt: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC),
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "replace_in_posts"
+	request.Params.Arguments = map[string]interface{}{
+		"post_ids":    []interface{}{float64(1)},
+		"pattern":     "  ",
+		"replacement": " ",
+	}
+	result, err := handleReplaceInPostsRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := resultText(t, result); result.IsError || !strings.Contains(text, "Replaced 2 matches") {
+		t.Fatalf("Expected the spaces to be replaced, but got %q", text)
+	}
+	if got, want := fake.post(1).Body, "1. clone\n2. build\n"; got != want {
+		t.Errorf("Expected %q, but got %q", want, got)
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func NewRestorePostVersionTool() server.ServerTool {
+	return server.ServerTool{
+		Tool:    newRestorePostVersionTool(),
+		Handler: handleRestorePostVersionRequest,
+	}
+}
+
+func newRestorePostVersionTool() mcp.Tool {
+	return mcp.NewTool(
+		"restore_post_version",
+		mcp.WithDescription("Restore the title, body, tags and draft state of a DocBase post from a version in the local history (see list_post_history). "+
+			"The scope is not changed. The current version is saved to the history first, so the restore can be undone too"),
+		withPostID("The ID of the post to restore"),
+		withInteger(
+			"version",
+			mcp.Required(),
+			mcp.Description("The version to restore"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean(
+			"notice",
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
+		),
+		withExpectedUpdatedAt(),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
+	)
+}
+
+// restorePostVersionArgs は restore_post_version の引数です
+type restorePostVersionArgs struct {
+	PostID  int64 `arg:"post_id,required" min:"1"`
+	Version int   `arg:"version,required" min:"1"`
+	Notice  *bool `arg:"notice"`
+	DryRun  bool  `arg:"dry_run"`
+
+	ExpectedUpdatedAt *timestamp `arg:"expected_updated_at"`
+}
+
+func handleRestorePostVersionRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	var args restorePostVersionArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+
+	version, err := findHistoryVersion(client, args.PostID, args.Version)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
+
+	// 空のタイトルと本文は「変更しない」という意味になるため、そのままでは戻せない
+	if version.Title == "" || version.Body == "" {
+		return newToolResultError("Version %d of post %d has an empty title or body, which cannot be restored through the DocBase API. "+
+			"Nothing was changed. Use update_post to write the post instead.", version.Version, args.PostID), nil
+	}
+
+	// 履歴の版の内容で update_post と同じ検査と確認を経て更新する
+	// タグのない版では、空のリストを送って今のタグを外す
+	draft := version.Draft
+	tags := append([]string{}, version.Tags...)
+	message := fmt.Sprintf("Post restored to version %d (the version updated at %s)!", version.Version, version.UpdatedAt.Format(time.RFC3339))
+	result, _ := updatePost(ctx, request, client, policy, updatePostArgs{
+		PostID:            args.PostID,
+		Title:             version.Title,
+		Body:              version.Body,
+		Draft:             &draft,
+		Notice:            args.Notice,
+		Tags:              tags,
+		DryRun:            args.DryRun,
+		ExpectedUpdatedAt: args.ExpectedUpdatedAt,
+	}, message)
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRestorePostVersion(t *testing.T) {
+	setTestTeams(t, docbase.Policy{})
+	SetStateDir(t.TempDir())
+	defer SetStateDir("")
+
+	updatedAt := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	client := docbase.NewDocBaseClient("example", "token")
+	for _, post := range []*docbase.GetPostResponse{
+		{PostID: 1, Title: "手順 v1", Body: "1. clone\n", Draft: true, Tags: []docbase.Tag{{Name: "手順"}}, UpdatedAt: updatedAt},
+		{PostID: 1, Title: "空の版", Body: "", UpdatedAt: updatedAt.Add(time.Minute)},
+	} {
+		if _, err := saveHistory(client, post, "update_post"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	fake := newFakeDocBase(t, docbase.GetPostResponse{
+		PostID:    1,
+		Title:     "手順 v3",
+		Body:      "1. clone\n2. build\n",
+		Scope:     docbase.ScopePrivate,
+		Tags:      []docbase.Tag{{Name: "手順"}, {Name: "古い"}},
+		UpdatedAt: updatedAt.Add(2 * time.Minute),
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "restore_post_version"
+	request.Params.Arguments = map[string]interface{}{"post_id": float64(1), "version": float64(1)}
+	result, err := handleRestorePostVersionRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := resultText(t, result); result.IsError || !strings.Contains(text, "Post restored to version 1") {
+		t.Fatalf("Expected the post to be restored, but got %q", text)
+	}
+
+	post := fake.post(1)
+	if post.Title != "手順 v1" || post.Body != "1. clone\n" || !post.Draft {
+		t.Errorf("Expected version 1 to be restored, but got %+v", post)
+	}
+	if len(post.Tags) != 1 || post.Tags[0].Name != "手順" {
+		t.Errorf("Expected the tags of version 1, but got %v", post.Tags)
+	}
+	// 戻す前の版も履歴に残るため、戻したこと自体を元に戻せる
+	history, err := loadHistory(client, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if last := history[len(history)-1]; len(history) != 3 || last.Title != "手順 v3" || last.Tool != "restore_post_version" {
+		t.Errorf("Expected the replaced version to be saved, but got %+v", history)
+	}
+
+	// 本文が空の版は、本文を変えない更新になってしまうため戻さない
+	request.Params.Arguments = map[string]interface{}{"post_id": float64(1), "version": float64(2)}
+	result, err = handleRestorePostVersionRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), "empty title or body") {
+		t.Errorf("Expected an error for the empty version, but got %q", resultText(t, result))
+	}
+	if len(fake.updates) != 1 {
+		t.Errorf("Expected only one update to be sent, but got %d", len(fake.updates))
+	}
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withInteger は整数の引数を定義します
+// mcp-go には integer 型の定義がないため、number 型として定義してから型を書き換えます
+func withInteger(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber(name, opts...)(t)
+		t.InputSchema.Properties[name].(map[string]interface{})["type"] = "integer"
+	}
+}
+
+// withPostID は必須の post_id 引数を定義します
+func withPostID(description string) mcp.ToolOption {
+	return withInteger(
+		"post_id",
+		mcp.Required(),
+		mcp.Min(1),
+		mcp.Description(description),
+	)
+}
+
+// withTags は tags 引数を定義します
+// 以前のカンマ区切りの文字列も bindArguments で受け付けます
+func withTags() mcp.ToolOption {
+	return mcp.WithArray(
+		"tags",
+		mcp.Items(map[string]interface{}{"type": "string"}),
+		mcp.Description("Tags of the post"),
+	)
+}
+
+// withScope は scope 引数を定義します
+func withScope(opts ...mcp.PropertyOption) mcp.ToolOption {
+	return mcp.WithString(
+		"scope",
+		append([]mcp.PropertyOption{
+			mcp.Enum(string(docbase.ScopeAll), string(docbase.ScopeGroup), string(docbase.ScopePrivate)),
+		}, opts...)...,
+	)
+}
+
+// withGroups は groups 引数を定義します
+func withGroups() mcp.ToolOption {
+	return mcp.WithArray(
+		"groups",
+		mcp.Items(map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer", "minimum": 1},
+				map[string]interface{}{"type": "string"},
+			},
+		}),
+		mcp.Description("IDs or names of the groups that can read the post (required if scope is 'group')"),
+	)
+}
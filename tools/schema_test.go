@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToolSchemas(t *testing.T) {
+	b, err := json.Marshal(newCreatePostTool())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var tool struct {
+		InputSchema struct {
+			Properties map[string]struct {
+				Type  string   `json:"type"`
+				Enum  []string `json:"enum"`
+				Items struct {
+					Type  string        `json:"type"`
+					AnyOf []interface{} `json:"anyOf"`
+				} `json:"items"`
+			} `json:"properties"`
+		} `json:"inputSchema"`
+	}
+	if err := json.Unmarshal(b, &tool); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	props := tool.InputSchema.Properties
+	if props["tags"].Type != "array" || props["tags"].Items.Type != "string" {
+		t.Errorf("Expected tags to be an array of strings, but got %+v", props["tags"])
+	}
+	if props["groups"].Type != "array" || len(props["groups"].Items.AnyOf) != 2 {
+		t.Errorf("Expected groups to be an array of IDs or names, but got %+v", props["groups"])
+	}
+	if len(props["scope"].Enum) != 3 {
+		t.Errorf("Expected scope to be an enum, but got %+v", props["scope"])
+	}
+
+	getPost := newGetPostTool()
+	if typ := getPost.InputSchema.Properties["post_id"].(map[string]interface{})["type"]; typ != "integer" {
+		t.Errorf("Expected post_id to be an integer, but got %v", typ)
+	}
+}
@@ -3,9 +3,6 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"os"
-	"strconv"
 
 	"docbase-mcp-server/docbase"
 
@@ -29,57 +26,48 @@ func newSearchPostsTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("The query to search for"),
 		),
-		mcp.WithString(
+		withInteger(
 			"page",
+			mcp.Min(1),
+			mcp.DefaultNumber(1),
 			mcp.Description("The page number (default is 1)"),
 		),
-		mcp.WithString(
+		withInteger(
 			"per_page",
+			mcp.Min(1),
+			mcp.Max(100),
+			mcp.DefaultNumber(20),
 			mcp.Description("Number of results per page (default is 20, max is 100)"),
 		),
+		withTeam(),
 	)
 }
 
-func handleSearchPostsRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client := docbase.NewDocBaseClient(
-		os.Getenv("DOCBASE_API_DOMAIN"),
-		os.Getenv("DOCBASE_API_TOKEN"),
-	)
-
-	// Get query parameter
-	queryStr, ok := request.Params.Arguments["query"]
-	if !ok {
-		return nil, errors.New("query is required")
-	}
-
-	searchQuery := docbase.SearchQuery{
-		Q:       queryStr.(string),
-		Page:    1,  // Default page is 1
-		PerPage: 20, // Default is 20 results per page
-	}
+// searchPostsArgs は search_posts の引数です
+type searchPostsArgs struct {
+	Query   string `arg:"query,required"`
+	Page    int    `arg:"page" default:"1" min:"1"`
+	PerPage int    `arg:"per_page" default:"20" min:"1" max:"100"` // APIの上限は100
+}
 
-	if pageStr, ok := request.Params.Arguments["page"]; ok {
-		page, err := strconv.Atoi(pageStr.(string))
-		if err != nil {
-			return nil, errors.New("page must be a number")
-		}
-		searchQuery.Page = page
+func handleSearchPostsRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
 	}
 
-	if perPageStr, ok := request.Params.Arguments["per_page"]; ok {
-		perPage, err := strconv.Atoi(perPageStr.(string))
-		if err != nil {
-			return nil, errors.New("per_page must be a number")
-		}
-		if perPage > 100 {
-			perPage = 100 // API limit is 100
-		}
-		searchQuery.PerPage = perPage
+	var args searchPostsArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
 	}
 
-	result, err := client.SearchPosts(ctx, searchQuery)
+	result, err := client.SearchPosts(ctx, docbase.SearchQuery{
+		Q:       args.Query,
+		Page:    args.Page,
+		PerPage: args.PerPage,
+	})
 	if err != nil {
-		return nil, err
+		return apiErrorResult(err, "the search"), nil
 	}
 
 	jsonResponse, err := json.MarshalIndent(result, "", "  ")
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// readOnlyTools はDocBaseを変更しないツールです
+// 新しいツールはここに追加しない限り、読み取り専用モードでは公開されません
+var readOnlyTools = map[string]bool{
+	"get_post_by_post_id": true,
+	"search_posts":        true,
+	"list_teams":          true,
+	"list_post_history":   true,
+	"diff_post_versions":  true,
+}
+
+// Filter は公開するツールの条件を表します
+type Filter struct {
+	// Enabled は公開するツールの名前です。空の場合はすべてのツールを対象にします
+	Enabled []string
+	// Disabled は公開しないツールの名前です。Enabled より優先します
+	Disabled []string
+	// ReadOnly が true の場合、DocBaseを変更するツールは公開しません
+	ReadOnly bool
+}
+
+// Select は all のうち filter の条件に合うツールを返します
+// 存在しないツールの名前が指定された場合はエラーを返します
+func Select(all []server.ServerTool, filter Filter) ([]server.ServerTool, error) {
+	known := make(map[string]bool, len(all))
+	for _, t := range all {
+		known[t.Tool.Name] = true
+	}
+
+	enabled := make(map[string]bool, len(filter.Enabled))
+	for _, name := range filter.Enabled {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown tool %q in the enabled tools", name)
+		}
+		enabled[name] = true
+	}
+	disabled := make(map[string]bool, len(filter.Disabled))
+	for _, name := range filter.Disabled {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown tool %q in the disabled tools", name)
+		}
+		disabled[name] = true
+	}
+
+	var selected []server.ServerTool
+	for _, t := range all {
+		name := t.Tool.Name
+		switch {
+		case len(enabled) > 0 && !enabled[name]:
+		case disabled[name]:
+		case filter.ReadOnly && !readOnlyTools[name]:
+		default:
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
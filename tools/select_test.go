@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSelect(t *testing.T) {
+	all := []server.ServerTool{
+		NewCreatePostTool(),
+		NewGetPostTool(),
+		NewSearchPostsTool(),
+		NewUpdatePostTool(),
+		NewListTeamsTool(),
+	}
+
+	names := func(tools []server.ServerTool) []string {
+		var names []string
+		for _, t := range tools {
+			names = append(names, t.Tool.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"create_post", "get_post_by_post_id", "search_posts", "update_post", "list_teams"}},
+		{"enabled", Filter{Enabled: []string{"get_post_by_post_id", "update_post"}}, []string{"get_post_by_post_id", "update_post"}},
+		{"disabled wins", Filter{Enabled: []string{"get_post_by_post_id", "update_post"}, Disabled: []string{"update_post"}}, []string{"get_post_by_post_id"}},
+		{"read-only", Filter{ReadOnly: true}, []string{"get_post_by_post_id", "search_posts", "list_teams"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := Select(all, tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := names(selected); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, but got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := Select(all, Filter{Disabled: []string{"delete_post"}}); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"docbase-mcp-server/docbase"
+)
+
+// fakeDocBase は投稿の取得と更新だけに答えるDocBase APIのテスト用のサーバーです
+type fakeDocBase struct {
+	mu    sync.Mutex
+	posts map[int64]*docbase.GetPostResponse
+	// updates は受け取った更新のリクエストです
+	updates []docbase.UpdatePostParam
+}
+
+// newFakeDocBase は posts を持つ fakeDocBase を起動し、example チームへのリクエストをそこに送るようにします
+// テストの終わりにサーバーを止め、送り先を戻します
+func newFakeDocBase(t *testing.T, posts ...docbase.GetPostResponse) *fakeDocBase {
+	t.Helper()
+	f := &fakeDocBase{posts: make(map[int64]*docbase.GetPostResponse)}
+	for i := range posts {
+		post := posts[i]
+		f.posts[post.PostID] = &post
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	target, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// クライアントはチームのドメインから api.docbase.io のURLを作るため、トランスポートで送り先を変える
+	transport := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+		return transport.RoundTrip(req)
+	})
+	t.Cleanup(func() {
+		http.DefaultTransport = transport
+		ts.Close()
+	})
+	return f
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (f *fakeDocBase) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/teams/example/posts/"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, ok := f.posts[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		var param docbase.UpdatePostParam
+		if err := json.NewDecoder(r.Body).Decode(&param); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.updates = append(f.updates, param)
+		if param.Title != "" {
+			post.Title = param.Title
+		}
+		if param.Body != "" {
+			post.Body = param.Body
+		}
+		if param.Draft != nil {
+			post.Draft = *param.Draft
+		}
+		if param.Tags != nil {
+			post.Tags = nil
+			for _, name := range *param.Tags {
+				post.Tags = append(post.Tags, docbase.Tag{Name: name})
+			}
+		}
+		if param.Scope != "" {
+			post.Scope = param.Scope
+		}
+		post.UpdatedAt = post.UpdatedAt.Add(time.Minute)
+	default:
+		http.Error(w, fmt.Sprintf("unexpected method %s", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+// post は id の投稿の今の内容を返します
+func (f *fakeDocBase) post(id int64) docbase.GetPostResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.posts[id]
+}
@@ -3,11 +3,8 @@ package tools
 import (
 	"context"
 	"docbase-mcp-server/docbase"
-	"errors"
+	"docbase-mcp-server/textdiff"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -24,11 +21,7 @@ func newUpdatePostTool() mcp.Tool {
 	return mcp.NewTool(
 		"update_post",
 		mcp.WithDescription("Update an existing post in DocBase"),
-		mcp.WithString(
-			"post_id",
-			mcp.Required(),
-			mcp.Description("The ID of the post to update"),
-		),
+		withPostID("The ID of the post to update"),
 		mcp.WithString(
 			"title",
 			mcp.Description("The title of the post"),
@@ -43,112 +36,232 @@ func newUpdatePostTool() mcp.Tool {
 		),
 		mcp.WithBoolean(
 			"notice",
-			mcp.Description("Whether to send notification or not"),
-		),
-		mcp.WithString(
-			"tags",
-			mcp.Description("Comma-separated list of tags"),
+			mcp.Description("Whether to send notification or not (default is the team's posting policy, false unless configured)"),
 		),
-		mcp.WithString(
-			"scope",
-			mcp.Description("Scope of the post: 'everyone', 'group', or 'private'"),
-		),
-		mcp.WithString(
-			"groups",
-			mcp.Description("Comma-separated list of group IDs (required if scope is 'group')"),
+		withTags(),
+		withScope(
+			mcp.Description("Who can read the post"),
 		),
+		withGroups(),
+		withExpectedUpdatedAt(),
+		withConfirmationToken(),
+		withDryRun(),
+		withTeam(),
 	)
 }
 
+// updatePostArgs は update_post の引数です。指定された項目だけを更新します
+type updatePostArgs struct {
+	PostID int64         `arg:"post_id,required" min:"1"`
+	Title  string        `arg:"title"`
+	Body   string        `arg:"body"`
+	Draft  *bool         `arg:"draft"`
+	Notice *bool         `arg:"notice"`
+	Tags   []string      `arg:"tags"` // 空のリストはタグをすべて外す
+	Scope  docbase.Scope `arg:"scope" enum:"everyone,group,private"`
+	Groups []groupRef    `arg:"groups"`
+	DryRun bool          `arg:"dry_run"`
+
+	ExpectedUpdatedAt *timestamp `arg:"expected_updated_at"`
+
+	// edit は Body の代わりに、読んだ版の本文から新しい本文を作ります (patch_post などで使います)
+	edit func(body string) (string, error)
+	// confirmed は呼び出し側でまとめて確認を済ませた場合に true にします (replace_in_posts で使います)
+	confirmed bool
+}
+
 func handleUpdatePostRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client := docbase.NewDocBaseClient(
-		os.Getenv("DOCBASE_API_DOMAIN"),
-		os.Getenv("DOCBASE_API_TOKEN"),
-	)
+	client, err := newClient(ctx, request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
+	}
 
-	// post_idは必須
-	postIDStr, ok := request.Params.Arguments["post_id"].(string)
-	if !ok || postIDStr == "" {
-		return nil, errors.New("post_id is required")
+	policy, err := teamPolicy(request)
+	if err != nil {
+		return newToolResultError("%v", err), nil
 	}
 
-	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	var args updatePostArgs
+	if err := bindArguments(request.Params.Arguments, &args); err != nil {
+		return newToolResultError("Invalid arguments: %v", err), nil
+	}
+
+	result, _ := updatePost(ctx, request, client, policy, args, "Post updated successfully!")
+	return result, nil
+}
+
+// updatePost は args の内容で投稿を更新します。update_post と restore_post_version が共有します
+// 確認トークンは request の引数に対して発行するため、呼び出したツールと同じ引数で再実行すれば確定できます
+// 更新した場合は、履歴に保存した更新前の版も返します
+func updatePost(ctx context.Context, request mcp.CallToolRequest, client *docbase.DocBaseClient, policy docbase.Policy, args updatePostArgs, message string) (*mcp.CallToolResult, *historyVersion) {
+	if args.Scope != "" {
+		if err := policy.CheckScope(args.Scope); err != nil {
+			return newToolResultError("%v", err), nil
+		}
+	}
+	// 通知は省略された場合もポリシーの値を明示して送る
+	notice := policy.DefaultNotice
+	if args.Notice != nil {
+		notice = *args.Notice
+	}
+
+	updateParam := docbase.UpdatePostParam{
+		Title:  args.Title,
+		Body:   args.Body,
+		Draft:  args.Draft,
+		Notice: &notice,
+		Scope:  args.Scope,
+	}
+	if args.Tags != nil {
+		updateParam.Tags = &args.Tags
+	}
+
+	// scopeがgroupの場合はgroupsパラメータが必要
+	if args.Scope == docbase.ScopeGroup {
+		if len(args.Groups) == 0 {
+			return newToolResultError("Invalid arguments: groups is required when scope is 'group'"), nil
+		}
+		var err error
+		updateParam.Groups, err = resolveGroups(ctx, client, args.Groups)
+		if err != nil {
+			return groupErrorResult(err), nil
+		}
+	}
+
+	// 更新前の版を履歴に残し、公開範囲や本文の変更を検査して確認するため、更新の直前に今の投稿を読む
+	// expected_updated_at が指定された場合は、読んだ後に変わっていないことも確かめる
+	current, err := client.GetPost(ctx, args.PostID)
 	if err != nil {
-		return nil, errors.New("post_id must be a valid number")
+		return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
 	}
+	versions.remember(client, current)
 
-	// UpdatePostParamを作成
-	updateParam := docbase.UpdatePostParam{}
+	// 読んだ後に更新されていた場合は、読んだ版がわかれば3方向でマージする
+	var mergeNote string
+	changed := args.ExpectedUpdatedAt != nil && !sameVersion(current.UpdatedAt, args.ExpectedUpdatedAt.Time)
+	var base postVersion
+	if changed {
+		var ok bool
+		base, ok = versions.lookup(client, args.PostID, args.ExpectedUpdatedAt.Time)
+		if !ok {
+			return conflictResult(current, args.ExpectedUpdatedAt.Time, updateParam.Body), nil
+		}
+	}
 
-	// titleが指定されていれば設定
-	if title, ok := request.Params.Arguments["title"].(string); ok && title != "" {
-		updateParam.Title = title
+	// 編集は読んだ版の本文に適用する。その後に更新されていれば、結果を今の本文とマージする
+	if args.edit != nil {
+		source := current.Body
+		if changed {
+			source = base.Body
+		}
+		body, err := args.edit(source)
+		if err != nil {
+			return newToolResultError("%v. Nothing was changed.", err), nil
+		}
+		if body == "" {
+			return newToolResultError("The edits would leave the body of post %d empty. Nothing was changed.", args.PostID), nil
+		}
+		updateParam.Body = body
 	}
 
-	// bodyが指定されていれば設定
-	if body, ok := request.Params.Arguments["body"].(string); ok && body != "" {
+	if changed {
+		merged, conflict := mergeUpdate(current, base, args.ExpectedUpdatedAt.Time, &updateParam)
+		if conflict != nil {
+			return conflict, nil
+		}
+		mergeNote = merged
+	}
+
+	// 公開範囲だけを変える場合も、今のタイトルと本文が新しい公開範囲で読まれるため検査する
+	scope, title, body := current.Scope, updateParam.Title, updateParam.Body
+	if args.Scope != "" {
+		scope = args.Scope
+		if title == "" {
+			title = current.Title
+		}
+		if body == "" {
+			body = current.Body
+		}
+	}
+	// 編集のツールは本文の一部だけを変えるため、今の本文にある内容は検査し直さない
+	// 検査し直すと、編集していない箇所まで伏せ字に書き換えてしまう
+	bodyField := contentField{Name: "body", Value: &body}
+	if args.edit != nil && args.Scope == "" {
+		bodyField.Known = current.Body
+	}
+	notes, blocked := filterContent(scope, contentField{Name: "title", Value: &title}, bodyField)
+	if blocked != nil {
+		return blocked, nil
+	}
+	if mergeNote != "" {
+		notes = append([]string{mergeNote}, notes...)
+	}
+	if title != current.Title {
+		updateParam.Title = title
+	}
+	if body != current.Body {
 		updateParam.Body = body
 	}
 
-	// draftが指定されていれば設定
-	if draft, ok := request.Params.Arguments["draft"].(bool); ok {
-		updateParam.Draft = &draft
-	}
-
-	// noticeが指定されていれば設定
-	if notice, ok := request.Params.Arguments["notice"].(bool); ok {
-		updateParam.Notice = &notice
-	}
-
-	// tagsが指定されていれば設定
-	if tagsStr, ok := request.Params.Arguments["tags"].(string); ok && tagsStr != "" {
-		tags := []string{}
-		for _, tag := range strings.Split(tagsStr, ",") {
-			tags = append(tags, strings.TrimSpace(tag))
-		}
-		updateParam.Tags = tags
-	}
-
-	// scopeが指定されていれば設定
-	if scopeStr, ok := request.Params.Arguments["scope"].(string); ok && scopeStr != "" {
-		var scope docbase.Scope
-		switch scopeStr {
-		case "everyone":
-			scope = docbase.ScopeAll
-		case "group":
-			scope = docbase.ScopeGroup
-		case "private":
-			scope = docbase.ScopePrivate
-		default:
-			return nil, errors.New("scope must be one of 'everyone', 'group', or 'private'")
-		}
-		updateParam.Scope = scope
-
-		// scopeがgroupの場合はgroupsパラメータが必要
-		if scope == docbase.ScopeGroup {
-			if groupsStr, ok := request.Params.Arguments["groups"].(string); ok && groupsStr != "" {
-				groups := []int{}
-				for _, groupStr := range strings.Split(groupsStr, ",") {
-					var groupID int
-					if _, err := fmt.Sscanf(strings.TrimSpace(groupStr), "%d", &groupID); err == nil {
-						groups = append(groups, groupID)
-					}
-				}
-				if len(groups) == 0 {
-					return nil, errors.New("at least one valid group ID is required when scope is 'group'")
-				}
-				updateParam.Groups = groups
-			} else {
-				return nil, errors.New("groups parameter is required when scope is 'group'")
+	var reasons []string
+	if args.Scope != "" {
+		var currentGroups []int
+		for _, g := range current.Groups {
+			currentGroups = append(currentGroups, g.ID)
+		}
+		reasons = visibilityChanges(current.Scope, currentGroups, args.Scope, updateParam.Groups, notice)
+	} else {
+		reasons = visibilityChanges("", nil, "", nil, notice)
+	}
+	reasons = append(reasons, draftPublication(current.Draft, args.Draft, scope)...)
+
+	if isDryRun(args.DryRun) {
+		groups := updateParam.Groups
+		if args.Scope == "" {
+			for _, g := range current.Groups {
+				groups = append(groups, g.ID)
 			}
 		}
+		d := dryRun{
+			Method:  "PATCH",
+			URL:     fmt.Sprintf("%s/posts/%d", client.BaseURL, args.PostID),
+			Payload: updateParam,
+			Details: scopeDetails(scope, groups, notice),
+			Reasons: reasons,
+			Notes:   notes,
+		}
+		if updateParam.Title != "" && updateParam.Title != current.Title {
+			d.Details = append(d.Details, fmt.Sprintf("Title: %q -> %q", current.Title, updateParam.Title))
+		}
+		if updateParam.Body != "" {
+			d.Diff = textdiff.Unified(current.Body, updateParam.Body,
+				fmt.Sprintf("post %d (current)", args.PostID), fmt.Sprintf("post %d (updated)", args.PostID), 3)
+		}
+		return d.result(), nil
+	}
+
+	// 公開範囲を広げる場合と通知する場合は、ユーザーの確認を経てから更新する
+	if !args.confirmed {
+		if result := requireConfirmation(request, reasons); result != nil {
+			return result, nil
+		}
+	}
+
+	// 履歴に残せなければ元に戻せないため、更新しない
+	saved, err := saveHistory(client, current, request.Params.Name)
+	if err != nil {
+		return newToolResultError("Failed to save the current version of post %d to the local history, so nothing was changed: %v", args.PostID, err), nil
 	}
 
 	// UpdatePost APIを呼び出し
-	post, err := client.UpdatePost(ctx, postID, updateParam)
+	post, err := client.UpdatePost(ctx, args.PostID, updateParam)
 	if err != nil {
-		return nil, err
+		return apiErrorResult(err, fmt.Sprintf("post %d", args.PostID)), nil
 	}
+	versions.remember(client, post)
 
-	return mcp.NewToolResultText(fmt.Sprintf("Post updated successfully!\nTitle: %s\nID: %d", post.Title, post.PostID)), nil
+	if saved != nil {
+		notes = append(notes, fmt.Sprintf("The previous version was saved as version %d of the local history (see list_post_history and restore_post_version).", saved.Version))
+	}
+	return mcp.NewToolResultText(withNotes(formatPostResult(message, post, notice), notes)), saved
 }
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"sync"
+	"time"
+
+	"docbase-mcp-server/docbase"
+)
+
+// maxPostVersions はマージの基準にするために覚えておく投稿の版の数です
+const maxPostVersions = 256
+
+// versions はこのサーバーが読み書きした投稿の版です
+// expected_updated_at の版の本文を、update_post の3方向のマージの基準にします
+var versions = &versionCache{entries: make(map[versionKey]postVersion)}
+
+type versionKey struct {
+	account   string
+	postID    int64
+	updatedAt int64
+}
+
+// postVersion はある時点の投稿のタイトルと本文です
+type postVersion struct {
+	Title string
+	Body  string
+}
+
+// versionCache は投稿の版を、古いものから捨てながら maxPostVersions 個まで覚えます
+type versionCache struct {
+	mu      sync.Mutex
+	entries map[versionKey]postVersion
+	order   []versionKey
+}
+
+func newVersionKey(account string, postID int64, updatedAt time.Time) versionKey {
+	// DocBaseの updated_at は秒単位のため、秒で比べる
+	return versionKey{account: account, postID: postID, updatedAt: updatedAt.Unix()}
+}
+
+// sameVersion は a と b が同じ版の更新日時かを返します
+// DocBaseの updated_at は秒単位のため、クライアントが秒未満を付けて送った場合も同じ版とみなす
+func sameVersion(a, b time.Time) bool {
+	return a.Unix() == b.Unix()
+}
+
+// remember は client で読み書きした post の版を覚えます
+func (c *versionCache) remember(client *docbase.DocBaseClient, post *docbase.GetPostResponse) {
+	if post == nil || post.UpdatedAt.IsZero() {
+		return
+	}
+	key := newVersionKey(accountID(client), post.PostID, post.UpdatedAt)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = postVersion{Title: post.Title, Body: post.Body}
+	for len(c.order) > maxPostVersions {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// lookup は updatedAt の時点の投稿の版を返します
+func (c *versionCache) lookup(client *docbase.DocBaseClient, postID int64, updatedAt time.Time) (postVersion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[newVersionKey(accountID(client), postID, updatedAt)]
+	return v, ok
+}
@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"docbase-mcp-server/auth"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const sessionIDHeader = "Mcp-Session-Id"
+
+// httpSession はStreamable HTTPで接続中のクライアントを表します
+type httpSession struct {
+	id            string
+	key           *auth.Key
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *httpSession) SessionID() string {
+	return s.id
+}
+
+func (s *httpSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// httpTransport はMCPのStreamable HTTPトランスポートの最小限の実装です
+// mcp-go v0.15 には含まれないため自前で実装しています
+// POSTへのレスポンスは常にJSONで返し、サーバからの通知はGETで開いたイベントストリームに流します
+type httpTransport struct {
+	server  *server.MCPServer
+	handler MessageHandler
+	opts    Options
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// ServeHTTP は ctx がキャンセルされるまで opts.Addr の /mcp でStreamable HTTPトランスポートを提供します
+func ServeHTTP(ctx context.Context, s *server.MCPServer, handler MessageHandler, opts Options) error {
+	t := &httpTransport{
+		server:   s,
+		handler:  handler,
+		opts:     opts,
+		sessions: make(map[string]*httpSession),
+	}
+
+	streamCtx, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealth)
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			t.handlePost(w, r)
+		case http.MethodGet:
+			reqCtx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			stop := context.AfterFunc(streamCtx, cancel)
+			defer stop()
+
+			t.handleGet(w, r.WithContext(reqCtx))
+		case http.MethodDelete:
+			t.handleDelete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	srv := &http.Server{Addr: opts.Addr, Handler: mux}
+	err := listenAndServe(ctx, srv, closeStreams)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.sessions {
+		t.server.UnregisterSession(id)
+	}
+
+	return err
+}
+
+// session はリクエストのセッションを返します。存在しないか他のクライアントのセッションの場合はエラーを返します
+func (t *httpTransport) session(w http.ResponseWriter, r *http.Request, key *auth.Key) (*httpSession, bool) {
+	t.mu.Lock()
+	session, ok := t.sessions[r.Header.Get(sessionIDHeader)]
+	t.mu.Unlock()
+
+	if !ok {
+		writeJSONRPCError(w, http.StatusNotFound, mcp.INVALID_PARAMS, "Invalid session ID")
+		return nil, false
+	}
+	if session.key != key {
+		writeJSONRPCError(w, http.StatusForbidden, mcp.INVALID_REQUEST, "Session belongs to another client")
+		return nil, false
+	}
+	return session, true
+}
+
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.opts.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var message json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.PARSE_ERROR, "Parse error")
+		return
+	}
+
+	var base struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(message, &base); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_REQUEST, "Batch requests are not supported")
+		return
+	}
+
+	var session *httpSession
+	if base.Method == "initialize" {
+		session = &httpSession{
+			id:            uuid.New().String(),
+			key:           key,
+			notifications: make(chan mcp.JSONRPCNotification, 100),
+		}
+		if err := t.server.RegisterSession(session); err != nil {
+			writeJSONRPCError(w, http.StatusInternalServerError, mcp.INTERNAL_ERROR, fmt.Sprintf("Session registration failed: %v", err))
+			return
+		}
+		t.mu.Lock()
+		t.sessions[session.id] = session
+		t.mu.Unlock()
+		w.Header().Set(sessionIDHeader, session.id)
+	} else {
+		if session, ok = t.session(w, r, key); !ok {
+			return
+		}
+	}
+
+	ctx := withCredentials(t.server.WithContext(r.Context(), session), session.key)
+	response := t.handler.HandleMessage(ctx, message)
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleGet はセッションへの通知をSSEで流します
+func (t *httpTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.opts.authenticate(w, r)
+	if !ok {
+		return
+	}
+	session, ok := t.session(w, r, key)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case notification := <-session.notifications:
+			data, err := json.Marshal(notification)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (t *httpTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.opts.authenticate(w, r)
+	if !ok {
+		return
+	}
+	session, ok := t.session(w, r, key)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	delete(t.sessions, session.id)
+	t.mu.Unlock()
+	t.server.UnregisterSession(session.id)
+	t.opts.sessionClosed(session.id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
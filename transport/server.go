@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"docbase-mcp-server/auth"
+	"docbase-mcp-server/docbase"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// shutdownTimeout は終了時に処理中のリクエストを待つ時間です
+const shutdownTimeout = 10 * time.Second
+
+// Options はHTTPベースのトランスポートの設定を表します
+type Options struct {
+	// Addr は待ち受けるアドレスです (例: ":8080")
+	Addr string
+	// BaseURL はSSEのendpointイベントで通知するURLの接頭辞です
+	// 空の場合は相対パスを通知します
+	BaseURL string
+	// SessionClosed はクライアントのセッションが終了したときに呼ばれます
+	SessionClosed func(sessionID string)
+	// Keys が設定されている場合、Authorization: Bearer ヘッダで認証されたクライアントだけを受け付けます
+	// セッションは作成したクライアントに紐付き、他のクライアントからは使えません
+	Keys *auth.Keys
+}
+
+// authenticate はリクエストを認証し、失敗した場合は401を返します
+// 認証が無効な場合は nil と true を返します
+func (o Options) authenticate(w http.ResponseWriter, r *http.Request) (*auth.Key, bool) {
+	if o.Keys == nil {
+		return nil, true
+	}
+
+	key, ok := o.Keys.Authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="docbase-mcp-server"`)
+		writeJSONRPCError(w, http.StatusUnauthorized, mcp.INVALID_REQUEST, "Unauthorized")
+		return nil, false
+	}
+	return key, true
+}
+
+// withCredentials はクライアントに割り当てられたDocBaseのAPIトークンを ctx に設定します
+func withCredentials(ctx context.Context, key *auth.Key) context.Context {
+	if key == nil || !key.HasDocBaseTokens() {
+		return ctx
+	}
+	return docbase.WithUserTokens(ctx, docbase.UserTokens{
+		Default: key.DocBaseToken,
+		Teams:   key.DocBaseTokens,
+	})
+}
+
+func (o Options) sessionClosed(sessionID string) {
+	if o.SessionClosed != nil {
+		o.SessionClosed(sessionID)
+	}
+}
+
+// handleHealth はロードバランサなどから死活監視に使われます
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// listenAndServe は ctx がキャンセルされるまで handler を提供し、キャンセル後は処理中のリクエストを待ってから終了します
+// onShutdown はサーバの停止を始める前に呼ばれ、長時間続く接続を閉じるのに使います
+func listenAndServe(ctx context.Context, srv *http.Server, onShutdown func()) error {
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", srv.Addr)
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down")
+	if onShutdown != nil {
+		onShutdown()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"docbase-mcp-server/auth"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sseSession はSSEで接続中のクライアントを表します
+// イベントストリームは mcp-go の SSEServer が管理し、ここに送られた通知はそのストリームに流します
+type sseSession struct {
+	id            string
+	key           *auth.Key
+	notifications chan mcp.JSONRPCNotification
+	done          chan struct{}
+}
+
+func (s *sseSession) SessionID() string {
+	return s.id
+}
+
+func (s *sseSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// sseTransport は mcp-go の SSEServer でイベントストリームを提供し、
+// クライアントから届いたメッセージは handler 経由で処理します
+type sseTransport struct {
+	server  *server.MCPServer
+	sse     *server.SSEServer
+	handler MessageHandler
+	opts    Options
+
+	mu       sync.Mutex
+	sessions map[string]*sseSession
+}
+
+// ServeSSE は ctx がキャンセルされるまで opts.Addr でSSEトランスポートを提供します
+// GET /sse でイベントストリームを開き、POST /message?sessionId=... でメッセージを送ります
+func ServeSSE(ctx context.Context, s *server.MCPServer, handler MessageHandler, opts Options) error {
+	srv := &http.Server{Addr: opts.Addr}
+	t := &sseTransport{
+		server:   s,
+		handler:  handler,
+		opts:     opts,
+		sessions: make(map[string]*sseSession),
+	}
+	t.sse = server.NewSSEServer(s, server.WithBaseURL(opts.BaseURL))
+
+	// mcp-go のイベントストリームは接続が切れるまで終わらないため、終了時にリクエストのコンテキストごと閉じる
+	streamCtx, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealth)
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		reqCtx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(streamCtx, cancel)
+		defer stop()
+
+		t.handleSSE(w, r.WithContext(reqCtx))
+	})
+	mux.HandleFunc("/message", t.handleMessage)
+	srv.Handler = mux
+
+	return listenAndServe(ctx, srv, closeStreams)
+}
+
+func (t *sseTransport) handleSSE(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.opts.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	sw := &endpointWriter{
+		ResponseWriter: w,
+		opened:         func(sessionID string) { t.open(sessionID, key) },
+	}
+	t.sse.ServeHTTP(sw, r)
+
+	if sw.sessionID != "" {
+		t.close(sw.sessionID)
+	}
+}
+
+func (t *sseTransport) open(sessionID string, key *auth.Key) {
+	session := &sseSession{
+		id:            sessionID,
+		key:           key,
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+		done:          make(chan struct{}),
+	}
+
+	t.mu.Lock()
+	t.sessions[sessionID] = session
+	t.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				if err := t.sse.SendEventToSession(sessionID, notification); err != nil {
+					log.Printf("Failed to send notification to session %s: %v", sessionID, err)
+				}
+			case <-session.done:
+				return
+			}
+		}
+	}()
+}
+
+func (t *sseTransport) close(sessionID string) {
+	t.mu.Lock()
+	session, ok := t.sessions[sessionID]
+	delete(t.sessions, sessionID)
+	t.mu.Unlock()
+
+	if ok {
+		close(session.done)
+		t.opts.sessionClosed(sessionID)
+	}
+}
+
+func (t *sseTransport) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONRPCError(w, http.StatusMethodNotAllowed, mcp.INVALID_REQUEST, "Method not allowed")
+		return
+	}
+
+	key, ok := t.opts.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	session, ok := t.sessions[r.URL.Query().Get("sessionId")]
+	t.mu.Unlock()
+	if !ok {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_PARAMS, "Invalid session ID")
+		return
+	}
+	if session.key != key {
+		writeJSONRPCError(w, http.StatusForbidden, mcp.INVALID_REQUEST, "Session belongs to another client")
+		return
+	}
+
+	var message json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.PARSE_ERROR, "Parse error")
+		return
+	}
+
+	ctx := withCredentials(t.server.WithContext(r.Context(), session), session.key)
+	response := t.handler.HandleMessage(ctx, message)
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// SSEトランスポートではレスポンスをイベントストリームで返す
+	if err := t.sse.SendEventToSession(session.id, response); err != nil {
+		log.Printf("Failed to send response to session %s: %v", session.id, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// endpointWriter は mcp-go が最初に送る endpoint イベントからセッションIDを読み取ります
+type endpointWriter struct {
+	http.ResponseWriter
+	opened    func(sessionID string)
+	sessionID string
+}
+
+func (w *endpointWriter) Write(p []byte) (int, error) {
+	if w.sessionID == "" && bytes.HasPrefix(p, []byte("event: endpoint\n")) {
+		if _, data, ok := bytes.Cut(p, []byte("data: ")); ok {
+			if u, err := url.Parse(string(bytes.TrimSpace(data))); err == nil {
+				if id := u.Query().Get("sessionId"); id != "" {
+					w.sessionID = id
+					w.opened(id)
+				}
+			}
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *endpointWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status int, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(mcp.NewJSONRPCError(nil, code, message, nil))
+}
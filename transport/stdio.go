@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MessageHandler はJSON-RPCメッセージを1つ処理します
+// *server.MCPServer もこのインターフェースを満たします
+type MessageHandler interface {
+	HandleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage
+}
+
+// stdioSession は標準入出力の唯一のクライアントを表します
+type stdioSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *stdioSession) SessionID() string {
+	return "stdio"
+}
+
+func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// ServeStdio は ctx がキャンセルされるか入力が終わるまで、標準入出力でMCPのメッセージを処理します
+// server.ServeStdio と異なり、メッセージを handler 経由で処理するため mcp-go が扱わないメソッドを横取りできます
+func ServeStdio(ctx context.Context, s *server.MCPServer, handler MessageHandler, stdin io.Reader, stdout io.Writer) error {
+	session := &stdioSession{
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
+	if err := s.RegisterSession(session); err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+	defer s.UnregisterSession(session.SessionID())
+	ctx = s.WithContext(ctx, session)
+
+	var mu sync.Mutex
+	write := func(message any) {
+		b, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Failed to marshal message: %v", err)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := fmt.Fprintf(stdout, "%s\n", b); err != nil {
+			log.Printf("Failed to write message: %v", err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				write(notification)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	lines := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(stdin)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read input: %w", err)
+		case line := <-lines:
+			var message json.RawMessage
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				write(mcp.NewJSONRPCError(nil, mcp.PARSE_ERROR, "Parse error", nil))
+				continue
+			}
+
+			if response := handler.HandleMessage(ctx, message); response != nil {
+				write(response)
+			}
+		}
+	}
+}